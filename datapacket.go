@@ -0,0 +1,112 @@
+package lksdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxDataPacketSize is the largest encoded DataPacket SafeUnmarshalDataPacket accepts,
+// it matches the default max message size of the data channels
+const MaxDataPacketSize = 65536
+
+var ErrDataPacketTooLarge = errors.New("data packet is too large")
+
+// SafeUnmarshalDataPacket decodes a DataPacket received from an untrusted peer.
+// It rejects oversized input and packets without a value, and never panics. Like all protobuf
+// enums, the kind is open: kinds unknown to this version, sent by newer peers, are accepted.
+func SafeUnmarshalDataPacket(b []byte) (*livekit.DataPacket, error) {
+	return safeUnmarshalDataPacket(b, proto.UnmarshalOptions{DiscardUnknown: true}.Unmarshal)
+}
+
+// safeUnmarshalDataPacketJSON is SafeUnmarshalDataPacket for the JSON encoding, used by text messages
+func safeUnmarshalDataPacketJSON(b []byte) (*livekit.DataPacket, error) {
+	return safeUnmarshalDataPacket(b, protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal)
+}
+
+func safeUnmarshalDataPacket(b []byte, unmarshal func([]byte, proto.Message) error) (packet *livekit.DataPacket, err error) {
+	if len(b) > MaxDataPacketSize {
+		return nil, ErrDataPacketTooLarge
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			packet, err = nil, fmt.Errorf("could not decode data packet: %v", r)
+		}
+	}()
+
+	packet = &livekit.DataPacket{}
+	if err = unmarshal(b, packet); err != nil {
+		return nil, err
+	}
+	if err = validateDataPacket(packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+func validateDataPacket(packet *livekit.DataPacket) error {
+	switch v := packet.Value.(type) {
+	case *livekit.DataPacket_User:
+		if v.User == nil {
+			return &MissingFieldError{Message: "DataPacket", Field: "user"}
+		}
+	case *livekit.DataPacket_Speaker:
+		if v.Speaker == nil {
+			return &MissingFieldError{Message: "DataPacket", Field: "speaker"}
+		}
+	default:
+		return &MissingFieldError{Message: "DataPacket", Field: "value"}
+	}
+	return nil
+}
+
+// OutgoingData is a user message to send on a data channel, along with its delivery parameters
+type OutgoingData struct {
+	Payload []byte
+	// Reliable messages are retransmitted and ordered, lossy ones are sent once
+	Reliable bool
+	// DestinationSIDs are the participants receiving the message, everyone in the room when empty
+	DestinationSIDs []string
+}
+
+// Kind returns the DataPacket kind matching the delivery of the message
+func (d *OutgoingData) Kind() livekit.DataPacket_Kind {
+	if d.Reliable {
+		return livekit.DataPacket_RELIABLE
+	}
+	return livekit.DataPacket_LOSSY
+}
+
+// Packet returns the DataPacket carrying the message
+func (d *OutgoingData) Packet() *livekit.DataPacket {
+	return &livekit.DataPacket{
+		Kind: d.Kind(),
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload:         d.Payload,
+				DestinationSids: d.DestinationSIDs,
+			},
+		},
+	}
+}
+
+// Encode returns the encoded DataPacket, as sent on the data channel. ErrDataPacketTooLarge is
+// returned when it is larger than MaxDataPacketSize, since receivers would reject it.
+func (d *OutgoingData) Encode() ([]byte, error) {
+	return encodeDataPacket(d.Packet())
+}
+
+func encodeDataPacket(packet *livekit.DataPacket) ([]byte, error) {
+	encoded, err := proto.Marshal(packet)
+	if err != nil {
+		return nil, err
+	}
+	if len(encoded) > MaxDataPacketSize {
+		return nil, ErrDataPacketTooLarge
+	}
+	return encoded, nil
+}
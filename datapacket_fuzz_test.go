@@ -0,0 +1,29 @@
+//go:build go1.18
+// +build go1.18
+
+package lksdk
+
+import (
+	"testing"
+)
+
+func FuzzSafeUnmarshalDataPacket(f *testing.F) {
+	for _, seed := range [][]byte{
+		{},
+		{0x08, 0x01},
+		{0x08, 0x07, 0x12, 0x00},
+		{0x12, 0x07, 0x12, 0x05, 'h', 'e', 'l', 'l', 'o'},
+		{0x12, 0xff, 0xff, 0xff, 0xff, 0x0f},
+		{0x1a, 0x04, 0x0a, 0x02, 0x0a, 0x00},
+		{0xfb, 0xff, 0xff, 0xff, 0x0f},
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		packet, err := SafeUnmarshalDataPacket(b)
+		if err == nil && packet.GetValue() == nil {
+			t.Fatal("decoded packet without a value")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSafeUnmarshalDataPacket(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		b, err := proto.Marshal(&livekit.DataPacket{
+			Kind:  livekit.DataPacket_LOSSY,
+			Value: &livekit.DataPacket_User{User: &livekit.UserPacket{Payload: []byte("hello")}},
+		})
+		require.NoError(t, err)
+
+		packet, err := SafeUnmarshalDataPacket(b)
+		require.NoError(t, err)
+		require.Equal(t, []byte("hello"), packet.GetUser().GetPayload())
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := SafeUnmarshalDataPacket(make([]byte, MaxDataPacketSize+1))
+		require.ErrorIs(t, err, ErrDataPacketTooLarge)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		packet, err := SafeUnmarshalDataPacket([]byte{0x08, 0x07, 0x12, 0x00})
+		require.NoError(t, err)
+		require.Equal(t, livekit.DataPacket_Kind(7), packet.Kind)
+		require.NotNil(t, packet.GetUser())
+	})
+
+	t.Run("no value", func(t *testing.T) {
+		_, err := SafeUnmarshalDataPacket([]byte{0x08, 0x01})
+		require.ErrorIs(t, err, ErrMissingField)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := SafeUnmarshalDataPacket([]byte{0x12, 0xff, 0xff, 0xff, 0xff, 0x0f})
+		require.Error(t, err)
+	})
+}
+
+func TestOutgoingData(t *testing.T) {
+	d := &OutgoingData{Payload: []byte("hello"), DestinationSIDs: []string{"PA_a"}}
+	require.Equal(t, livekit.DataPacket_LOSSY, d.Kind())
+	d.Reliable = true
+	require.Equal(t, livekit.DataPacket_RELIABLE, d.Kind())
+
+	b, err := d.Encode()
+	require.NoError(t, err)
+	packet, err := SafeUnmarshalDataPacket(b)
+	require.NoError(t, err)
+	require.Equal(t, livekit.DataPacket_RELIABLE, packet.Kind)
+	require.Equal(t, []byte("hello"), packet.GetUser().GetPayload())
+	require.Equal(t, []string{"PA_a"}, packet.GetUser().GetDestinationSids())
+
+	d.Payload = make([]byte, MaxDataPacketSize)
+	_, err = d.Encode()
+	require.ErrorIs(t, err, ErrDataPacketTooLarge)
+}
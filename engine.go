@@ -6,8 +6,6 @@ import (
 
 	"github.com/pion/webrtc/v3"
 	"go.uber.org/atomic"
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/protocol/livekit"
 )
@@ -309,6 +307,8 @@ func (e *RTCEngine) handleLocalTrackPublished(res *livekit.TrackPublishedRespons
 }
 
 func (e *RTCEngine) handleDataPacket(msg webrtc.DataChannelMessage) {
+	// packets without a value, such as those of kinds added by newer servers, are dropped
+	// along with malformed ones, as they carry nothing to deliver
 	packet, err := e.readDataPacket(msg)
 	if err != nil {
 		return
@@ -326,13 +326,10 @@ func (e *RTCEngine) handleDataPacket(msg webrtc.DataChannelMessage) {
 }
 
 func (e *RTCEngine) readDataPacket(msg webrtc.DataChannelMessage) (*livekit.DataPacket, error) {
-	dataPacket := &livekit.DataPacket{}
 	if msg.IsString {
-		err := protojson.Unmarshal(msg.Data, dataPacket)
-		return dataPacket, err
+		return safeUnmarshalDataPacketJSON(msg.Data)
 	}
-	err := proto.Unmarshal(msg.Data, dataPacket)
-	return dataPacket, err
+	return SafeUnmarshalDataPacket(msg.Data)
 }
 
 func (e *RTCEngine) handleDisconnect() {
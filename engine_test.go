@@ -0,0 +1,75 @@
+package lksdk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRTCEngine_HandleDataPacket(t *testing.T) {
+	var received []*livekit.UserPacket
+	e := &RTCEngine{}
+	e.OnDataReceived = func(userPacket *livekit.UserPacket) {
+		received = append(received, userPacket)
+	}
+	packet := &livekit.DataPacket{
+		Kind:  livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{User: &livekit.UserPacket{Payload: []byte("hello")}},
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		received = nil
+		b, err := proto.Marshal(packet)
+		require.NoError(t, err)
+		e.handleDataPacket(webrtc.DataChannelMessage{Data: b})
+		require.Len(t, received, 1)
+		require.Equal(t, []byte("hello"), received[0].Payload)
+
+		e.handleDataPacket(webrtc.DataChannelMessage{Data: make([]byte, MaxDataPacketSize+1)})
+		require.Len(t, received, 1)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		received = nil
+		b, err := protojson.Marshal(packet)
+		require.NoError(t, err)
+		e.handleDataPacket(webrtc.DataChannelMessage{IsString: true, Data: b})
+		require.Len(t, received, 1)
+		require.Equal(t, []byte("hello"), received[0].Payload)
+
+		// the same checks as binary packets apply
+		for _, data := range []string{
+			`{"kind":"LOSSY"}`,
+			`{"kind":"LOSSY","user":{"payload":"` + strings.Repeat("A", MaxDataPacketSize) + `"}}`,
+		} {
+			e.handleDataPacket(webrtc.DataChannelMessage{IsString: true, Data: []byte(data)})
+		}
+		require.Len(t, received, 1)
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		received = nil
+		b, err := proto.Marshal(&livekit.DataPacket{
+			Kind:  livekit.DataPacket_Kind(7),
+			Value: &livekit.DataPacket_User{User: &livekit.UserPacket{Payload: []byte("hello")}},
+		})
+		require.NoError(t, err)
+		e.handleDataPacket(webrtc.DataChannelMessage{Data: b})
+		e.handleDataPacket(webrtc.DataChannelMessage{IsString: true, Data: []byte(`{"kind":7,"user":{"payload":"aGVsbG8="}}`)})
+		require.Len(t, received, 2)
+		require.Equal(t, []byte("hello"), received[1].Payload)
+	})
+
+	t.Run("unknown value", func(t *testing.T) {
+		received = nil
+		// kind LOSSY, and a value in a field unknown to this version, as sent by newer servers
+		e.handleDataPacket(webrtc.DataChannelMessage{Data: []byte{0x08, 0x01, 0x7a, 0x00}})
+		e.handleDataPacket(webrtc.DataChannelMessage{IsString: true, Data: []byte(`{"kind":"LOSSY","future":{}}`)})
+		require.Empty(t, received)
+	})
+}
@@ -1,6 +1,9 @@
 package lksdk
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrConnectionTimeout        = errors.New("could not connect after timeout")
@@ -10,4 +13,42 @@ var (
 	ErrUnsupportedSimulcastKind = errors.New("simulcast is only supported for video")
 	ErrInvalidSimulcastTrack    = errors.New("simulcast track was not initiated correctly")
 	ErrCannotFindTrack          = errors.New("could not find the track")
+	ErrInvalidEnum              = errors.New("invalid enum value")
+	ErrMissingField             = errors.New("missing required field")
+	ErrUnknownField             = errors.New("unknown field")
+	ErrNotMediaTrack            = errors.New("track is not an audio or video track")
+	ErrInvalidSID               = errors.New("invalid SID")
+	ErrInvalidTURNCredentials   = errors.New("TURN username and password must not be empty")
+	ErrUnsupportedSignalMessage = errors.New("unsupported signal message type")
+	ErrMalformedBatch           = errors.New("malformed length-delimited message")
+	ErrDuplicateTrackSID        = errors.New("duplicate track SID")
+	ErrTrackCodecMismatch       = errors.New("codec does not match the track type")
 )
+
+// InvalidEnumError is returned when a protocol enum holds an unknown value, it wraps ErrInvalidEnum
+type InvalidEnumError struct {
+	EnumName string
+	Value    int32
+}
+
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("%s: %d is not a valid %s", ErrInvalidEnum, e.Value, e.EnumName)
+}
+
+func (e *InvalidEnumError) Unwrap() error {
+	return ErrInvalidEnum
+}
+
+// MissingFieldError is returned when a required field of a protocol message is not set, it wraps ErrMissingField
+type MissingFieldError struct {
+	Message string
+	Field   string
+}
+
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("%s: %s.%s", ErrMissingField, e.Message, e.Field)
+}
+
+func (e *MissingFieldError) Unwrap() error {
+	return ErrMissingField
+}
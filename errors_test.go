@@ -0,0 +1,23 @@
+package lksdk
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrors(t *testing.T) {
+	err := fmt.Errorf("parsing track: %w", &InvalidEnumError{EnumName: "TrackType", Value: 7})
+	require.True(t, errors.Is(err, ErrInvalidEnum))
+	var enumErr *InvalidEnumError
+	require.True(t, errors.As(err, &enumErr))
+	require.Equal(t, int32(7), enumErr.Value)
+	require.Equal(t, "parsing track: invalid enum value: 7 is not a valid TrackType", err.Error())
+
+	err = &MissingFieldError{Message: "ParticipantInfo", Field: "identity"}
+	require.True(t, errors.Is(err, ErrMissingField))
+	require.False(t, errors.Is(err, ErrInvalidEnum))
+	require.Equal(t, "missing required field: ParticipantInfo.identity", err.Error())
+}
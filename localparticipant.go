@@ -229,6 +229,29 @@ func (p *LocalParticipant) PublishData(data []byte, kind livekit.DataPacket_Kind
 	return nil
 }
 
+// PublishOutgoingData sends a user message on the reliable or lossy data channel, depending on its delivery.
+// Unlike PublishData, messages larger than MaxDataPacketSize once encoded are rejected with ErrDataPacketTooLarge.
+func (p *LocalParticipant) PublishOutgoingData(data *OutgoingData) error {
+	packet := data.Packet()
+	// this is enforced on the server side, setting for completeness
+	packet.GetUser().ParticipantSid = p.sid
+
+	if err := p.engine.ensurePublisherConnected(true); err != nil {
+		return err
+	}
+
+	// encode packet
+	encoded, err := encodeDataPacket(packet)
+	if err != nil {
+		return err
+	}
+
+	if data.Reliable {
+		return p.engine.reliableDC.Send(encoded)
+	}
+	return p.engine.lossyDC.Send(encoded)
+}
+
 func (p *LocalParticipant) UnpublishTrack(sid string) error {
 	obj, loaded := p.tracks.LoadAndDelete(sid)
 	if !loaded {
@@ -3,7 +3,6 @@ package lksdk
 import (
 	"context"
 	"io"
-	"strings"
 	"sync"
 	"time"
 
@@ -432,20 +431,20 @@ func (s *LocalSampleTrack) writeWorker(provider SampleProvider, onComplete func(
 
 // duplicated from pion mediaengine.go
 func payloaderForCodec(codec webrtc.RTPCodecCapability) (rtp.Payloader, error) {
-	switch strings.ToLower(codec.MimeType) {
-	case strings.ToLower(webrtc.MimeTypeH264):
+	switch NormalizeCodecMime(codec.MimeType) {
+	case webrtc.MimeTypeH264:
 		return &codecs.H264Payloader{}, nil
-	case strings.ToLower(webrtc.MimeTypeOpus):
+	case webrtc.MimeTypeOpus:
 		return &codecs.OpusPayloader{}, nil
-	case strings.ToLower(webrtc.MimeTypeVP8):
+	case webrtc.MimeTypeVP8:
 		return &codecs.VP8Payloader{
 			EnablePictureID: true,
 		}, nil
-	case strings.ToLower(webrtc.MimeTypeVP9):
+	case webrtc.MimeTypeVP9:
 		return &codecs.VP9Payloader{}, nil
-	case strings.ToLower(webrtc.MimeTypeG722):
+	case webrtc.MimeTypeG722:
 		return &codecs.G722Payloader{}, nil
-	case strings.ToLower(webrtc.MimeTypePCMU), strings.ToLower(webrtc.MimeTypePCMA):
+	case webrtc.MimeTypePCMU, webrtc.MimeTypePCMA:
 		return &codecs.G711Payloader{}, nil
 	default:
 		return nil, webrtc.ErrNoPayloaderForCodec
@@ -0,0 +1,112 @@
+package lksdk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxParticipantSize bounds the size of the messages read by ParticipantReader, like protodelim does
+const maxParticipantSize = 4 << 20
+
+// MarshalParticipants encodes participants as a stream of messages, each prefixed with its size
+// as a varint. The format is the one of protodelim, so the stream can be decoded incrementally
+// or appended to without re-encoding the participants already written.
+func MarshalParticipants(infos []*livekit.ParticipantInfo) ([]byte, error) {
+	sizes := make([]int, len(infos))
+	total := 0
+	for i, info := range infos {
+		sizes[i] = proto.Size(info)
+		total += protowire.SizeVarint(uint64(sizes[i])) + sizes[i]
+	}
+
+	b := make([]byte, 0, total)
+	opts := proto.MarshalOptions{}
+	for i, info := range infos {
+		b = protowire.AppendVarint(b, uint64(sizes[i]))
+		var err error
+		if b, err = opts.MarshalAppend(b, info); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalParticipants decodes participants encoded with MarshalParticipants. It returns an error
+// wrapping ErrMalformedBatch if a size prefix is invalid or a message is truncated.
+func UnmarshalParticipants(b []byte) ([]*livekit.ParticipantInfo, error) {
+	var infos []*livekit.ParticipantInfo
+	for len(b) > 0 {
+		size, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedBatch, protowire.ParseError(n))
+		}
+		b = b[n:]
+		if size > uint64(len(b)) {
+			return nil, fmt.Errorf("%w: message of %d bytes truncated to %d", ErrMalformedBatch, size, len(b))
+		}
+
+		info := &livekit.ParticipantInfo{}
+		if err := proto.Unmarshal(b[:size], info); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+		b = b[size:]
+	}
+	return infos, nil
+}
+
+// ParticipantReader decodes participants from a stream in the format of MarshalParticipants,
+// one message at a time
+type ParticipantReader struct {
+	r byteReader
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// NewParticipantReader returns a ParticipantReader reading from r. r is buffered unless it
+// implements io.ByteReader, so it should not be read from elsewhere.
+func NewParticipantReader(r io.Reader) *ParticipantReader {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &ParticipantReader{r: br}
+}
+
+// Next returns the next participant of the stream, or io.EOF at the end of the stream. It returns
+// an error wrapping ErrMalformedBatch if a size prefix is invalid, larger than 4MiB, or the stream
+// ends within a message.
+func (p *ParticipantReader) Next() (*livekit.ParticipantInfo, error) {
+	size, err := binary.ReadUvarint(p.r)
+	switch {
+	case err == io.EOF:
+		return nil, io.EOF
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrMalformedBatch, err)
+	case size > maxParticipantSize:
+		return nil, fmt.Errorf("%w: message of %d bytes exceeds %d", ErrMalformedBatch, size, maxParticipantSize)
+	}
+
+	b := make([]byte, size)
+	if n, err := io.ReadFull(p.r, b); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: message of %d bytes truncated to %d", ErrMalformedBatch, size, n)
+	} else if err != nil {
+		return nil, err
+	}
+
+	info := &livekit.ParticipantInfo{}
+	if err := proto.Unmarshal(b, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
@@ -0,0 +1,73 @@
+package lksdk
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMarshalParticipants(t *testing.T) {
+	infos := []*livekit.ParticipantInfo{
+		{Sid: "PA_1", Identity: "a", Tracks: []*livekit.TrackInfo{{Sid: "TR_1"}}},
+		{},
+		{Sid: "PA_2", Identity: "b", Metadata: "meta"},
+	}
+
+	b, err := MarshalParticipants(infos)
+	require.NoError(t, err)
+
+	decoded, err := UnmarshalParticipants(b)
+	require.NoError(t, err)
+	require.Len(t, decoded, len(infos))
+	for i := range infos {
+		require.True(t, proto.Equal(infos[i], decoded[i]))
+	}
+
+	_, err = UnmarshalParticipants(b[:len(b)-1])
+	require.ErrorIs(t, err, ErrMalformedBatch)
+	_, err = UnmarshalParticipants([]byte{0x80})
+	require.ErrorIs(t, err, ErrMalformedBatch)
+
+	b, err = MarshalParticipants(nil)
+	require.NoError(t, err)
+	require.Empty(t, b)
+	decoded, err = UnmarshalParticipants(b)
+	require.NoError(t, err)
+	require.Empty(t, decoded)
+}
+
+func TestParticipantReader(t *testing.T) {
+	infos := []*livekit.ParticipantInfo{
+		{Sid: "PA_1", Identity: "a"},
+		{},
+		{Sid: "PA_2", Identity: "b", Metadata: "meta"},
+	}
+	b, err := MarshalParticipants(infos)
+	require.NoError(t, err)
+
+	r := NewParticipantReader(io.MultiReader(bytes.NewReader(b[:3]), bytes.NewReader(b[3:])))
+	for _, info := range infos {
+		decoded, err := r.Next()
+		require.NoError(t, err)
+		require.True(t, proto.Equal(info, decoded))
+	}
+	_, err = r.Next()
+	require.Equal(t, io.EOF, err)
+
+	r = NewParticipantReader(bytes.NewReader(b[:len(b)-1]))
+	for range infos[1:] {
+		_, err = r.Next()
+		require.NoError(t, err)
+	}
+	_, err = r.Next()
+	require.ErrorIs(t, err, ErrMalformedBatch, "truncated message")
+
+	_, err = NewParticipantReader(bytes.NewReader([]byte{0x80})).Next()
+	require.ErrorIs(t, err, ErrMalformedBatch, "truncated size")
+	_, err = NewParticipantReader(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x04})).Next()
+	require.ErrorIs(t, err, ErrMalformedBatch, "message too large")
+}
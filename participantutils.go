@@ -0,0 +1,248 @@
+package lksdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UpsertParticipantTrack replaces the track with the same SID in the participant's tracks,
+// or appends it if the participant does not have it yet. The tracks are copied to a new slice,
+// so that copies of the previous one, such as snapshots, are not modified. A nil participant or
+// track is a no-op.
+func UpsertParticipantTrack(p *livekit.ParticipantInfo, track *livekit.TrackInfo) {
+	if p == nil || track == nil {
+		return
+	}
+
+	tracks := make([]*livekit.TrackInfo, len(p.Tracks), len(p.Tracks)+1)
+	copy(tracks, p.Tracks)
+	for i, t := range tracks {
+		if t.GetSid() == track.Sid {
+			tracks[i] = track
+			p.Tracks = tracks
+			return
+		}
+	}
+	p.Tracks = append(tracks, track)
+}
+
+// RemoveParticipantTrack removes the track with the given SID, returns false if the participant does not have it.
+// Like UpsertParticipantTrack, it copies the tracks to a new slice, and is nil-safe.
+func RemoveParticipantTrack(p *livekit.ParticipantInfo, sid string) bool {
+	for i, t := range p.GetTracks() {
+		if t.GetSid() == sid {
+			tracks := make([]*livekit.TrackInfo, 0, len(p.Tracks)-1)
+			tracks = append(tracks, p.Tracks[:i]...)
+			p.Tracks = append(tracks, p.Tracks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ParticipantIdentityEqualFold reports whether the identity of the participant equals id under Unicode
+// case folding. It is nil-safe: a nil participant has an empty identity.
+func ParticipantIdentityEqualFold(p *livekit.ParticipantInfo, id string) bool {
+	return strings.EqualFold(p.GetIdentity(), id)
+}
+
+// FindByIdentityFold returns the first participant whose identity matches id with ParticipantIdentityEqualFold,
+// or nil. An exact match is preferred over one differing in case.
+func FindByIdentityFold(participants []*livekit.ParticipantInfo, id string) *livekit.ParticipantInfo {
+	var folded *livekit.ParticipantInfo
+	for _, p := range participants {
+		switch {
+		case p == nil:
+		case p.Identity == id:
+			return p
+		case folded == nil && ParticipantIdentityEqualFold(p, id):
+			folded = p
+		}
+	}
+	return folded
+}
+
+// SetParticipantPermission sets the permission of the participant to a copy of perm, a nil perm clears it
+func SetParticipantPermission(p *livekit.ParticipantInfo, perm *livekit.ParticipantPermission) {
+	if perm == nil {
+		p.Permission = nil
+		return
+	}
+	p.Permission = proto.Clone(perm).(*livekit.ParticipantPermission)
+}
+
+// ParticipantCanPublish reports whether the participant is allowed to publish tracks.
+// It is nil-safe: a nil participant, or one without permission, can't publish.
+func ParticipantCanPublish(p *livekit.ParticipantInfo) bool {
+	return p.GetPermission().GetCanPublish()
+}
+
+// ParticipantCanPublishData reports whether the participant is allowed to publish data.
+// It is nil-safe: a nil participant, or one without permission, can't publish data.
+func ParticipantCanPublishData(p *livekit.ParticipantInfo) bool {
+	return p.GetPermission().GetCanPublishData()
+}
+
+// ParticipantCanSubscribe reports whether the participant is allowed to subscribe to tracks.
+// It is nil-safe: a nil participant, or one without permission, can't subscribe.
+func ParticipantCanSubscribe(p *livekit.ParticipantInfo) bool {
+	return p.GetPermission().GetCanSubscribe()
+}
+
+// ValidateParticipantTracks returns an error wrapping ErrDuplicateTrackSID if several tracks of the
+// participant have the same SID. It is nil-safe.
+func ValidateParticipantTracks(p *livekit.ParticipantInfo) error {
+	seen := make(map[string]int, len(p.GetTracks()))
+	for i, t := range p.GetTracks() {
+		if first, ok := seen[t.GetSid()]; ok {
+			return fmt.Errorf("%w: %q at indexes %d and %d of participant %q", ErrDuplicateTrackSID, t.GetSid(), first, i, p.GetIdentity())
+		}
+		seen[t.GetSid()] = i
+	}
+	return nil
+}
+
+// DedupeParticipantTracks removes the tracks whose SID appears again later in the participant's tracks,
+// keeping the last occurrence of each SID in place. It returns the number of tracks removed, and is nil-safe.
+func DedupeParticipantTracks(p *livekit.ParticipantInfo) int {
+	if len(p.GetTracks()) < 2 {
+		return 0
+	}
+
+	last := make(map[string]int, len(p.Tracks))
+	for i, t := range p.Tracks {
+		last[t.GetSid()] = i
+	}
+	if len(last) == len(p.Tracks) {
+		return 0
+	}
+
+	tracks := make([]*livekit.TrackInfo, 0, len(last))
+	for i, t := range p.Tracks {
+		if last[t.GetSid()] == i {
+			tracks = append(tracks, t)
+		}
+	}
+	removed := len(p.Tracks) - len(tracks)
+	p.Tracks = tracks
+	return removed
+}
+
+// FitInBudget greedily picks participants, in order, whose combined wire size stays within maxBytes.
+// Participants that did not fit are returned in rest. Framing overhead of the enclosing message
+// is not accounted for.
+func FitInBudget(infos []*livekit.ParticipantInfo, maxBytes int) (fit []*livekit.ParticipantInfo, rest []*livekit.ParticipantInfo) {
+	remaining := maxBytes
+	for _, info := range infos {
+		if size := EstimatedWireSize(info); size <= remaining {
+			fit = append(fit, info)
+			remaining -= size
+		} else {
+			rest = append(rest, info)
+		}
+	}
+	return
+}
+
+// ParticipantFirstTrack returns the first track of the participant.
+// It is nil-safe: it returns nil if p is nil or has no tracks.
+func ParticipantFirstTrack(p *livekit.ParticipantInfo) *livekit.TrackInfo {
+	if tracks := p.GetTracks(); len(tracks) > 0 {
+		return tracks[0]
+	}
+	return nil
+}
+
+// ParticipantUnmutedTracks returns the tracks of the participant that are not muted, in order.
+// It is nil-safe: it returns nil if p is nil.
+func ParticipantUnmutedTracks(p *livekit.ParticipantInfo) []*livekit.TrackInfo {
+	var tracks []*livekit.TrackInfo
+	for _, t := range p.GetTracks() {
+		if !t.GetMuted() {
+			tracks = append(tracks, t)
+		}
+	}
+	return tracks
+}
+
+// ParticipantUnmutedTracksOfType returns the tracks of the given type that are not muted, in order.
+// It is nil-safe: it returns nil if p is nil.
+func ParticipantUnmutedTracksOfType(p *livekit.ParticipantInfo, trackType livekit.TrackType) []*livekit.TrackInfo {
+	var tracks []*livekit.TrackInfo
+	for _, t := range p.GetTracks() {
+		if !t.GetMuted() && t.GetType() == trackType {
+			tracks = append(tracks, t)
+		}
+	}
+	return tracks
+}
+
+// ParticipantFingerprint returns a stable hash of the participant's identity and track SIDs,
+// in any order. Other fields, such as the participant SID, state or track mutes, are ignored
+// so that the same participant can be recognized across reconnects.
+func ParticipantFingerprint(p *livekit.ParticipantInfo) string {
+	view := &livekit.ParticipantInfo{Identity: p.GetIdentity()}
+	for _, t := range p.GetTracks() {
+		view.Tracks = append(view.Tracks, &livekit.TrackInfo{Sid: t.Sid})
+	}
+	sort.Slice(view.Tracks, func(i, j int) bool {
+		return view.Tracks[i].Sid < view.Tracks[j].Sid
+	})
+
+	// marshaling a message with only these fields can't fail
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(view)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParticipantToMap converts the fields set in p to a map keyed by their JSON names, for structured logging.
+// Tracks and permissions are converted to nested maps.
+func ParticipantToMap(p *livekit.ParticipantInfo) map[string]interface{} {
+	return messageToMap(p.ProtoReflect())
+}
+
+// ParticipantMetadataAs decodes the participant's JSON metadata into v.
+// Empty metadata is a no-op, v is left unchanged.
+func ParticipantMetadataAs(p *livekit.ParticipantInfo, v interface{}) error {
+	return unmarshalMetadata(p.GetMetadata(), v)
+}
+
+// SetParticipantMetadataFrom sets the participant's metadata to the JSON encoding of v.
+// A nil v clears the metadata.
+func SetParticipantMetadataFrom(p *livekit.ParticipantInfo, v interface{}) error {
+	metadata, err := marshalMetadata(v)
+	if err != nil {
+		return err
+	}
+	p.Metadata = metadata
+	return nil
+}
+
+// ParticipantsEquivalent returns true when a and b are equal, except for the fields named in
+// ignore. Fields are named as in the protocol definition, such as "joined_at", names that do
+// not match a field are ignored. Two nil participants are equivalent.
+func ParticipantsEquivalent(a, b *livekit.ParticipantInfo, ignore ...string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if len(ignore) == 0 {
+		return proto.Equal(a, b)
+	}
+
+	a, b = proto.Clone(a).(*livekit.ParticipantInfo), proto.Clone(b).(*livekit.ParticipantInfo)
+	fields := a.ProtoReflect().Descriptor().Fields()
+	for _, name := range ignore {
+		if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+			a.ProtoReflect().Clear(fd)
+			b.ProtoReflect().Clear(fd)
+		}
+	}
+	return proto.Equal(a, b)
+}
@@ -0,0 +1,235 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParticipantTracks(t *testing.T) {
+	p := &livekit.ParticipantInfo{}
+
+	UpsertParticipantTrack(p, &livekit.TrackInfo{Sid: "TR_1"})
+	UpsertParticipantTrack(p, &livekit.TrackInfo{Sid: "TR_2"})
+	UpsertParticipantTrack(p, &livekit.TrackInfo{Sid: "TR_1", Muted: true})
+	require.Len(t, p.Tracks, 2)
+	require.True(t, p.Tracks[0].Muted)
+
+	require.True(t, RemoveParticipantTrack(p, "TR_1"))
+	require.False(t, RemoveParticipantTrack(p, "TR_1"))
+	require.Len(t, p.Tracks, 1)
+	require.Equal(t, "TR_2", p.Tracks[0].Sid)
+
+	t.Run("nil", func(t *testing.T) {
+		UpsertParticipantTrack(p, nil)
+		UpsertParticipantTrack(nil, &livekit.TrackInfo{Sid: "TR_1"})
+		require.Len(t, p.Tracks, 1)
+		require.False(t, RemoveParticipantTrack(nil, "TR_1"))
+	})
+
+	t.Run("snapshots are kept", func(t *testing.T) {
+		p := &livekit.ParticipantInfo{}
+		for _, sid := range []string{"TR_1", "TR_2", "TR_3"} {
+			UpsertParticipantTrack(p, &livekit.TrackInfo{Sid: sid})
+		}
+		snapshot := p.Tracks
+
+		require.True(t, RemoveParticipantTrack(p, "TR_1"))
+		UpsertParticipantTrack(p, &livekit.TrackInfo{Sid: "TR_2", Muted: true})
+		require.Equal(t, []string{"TR_1", "TR_2", "TR_3"}, []string{snapshot[0].Sid, snapshot[1].Sid, snapshot[2].Sid})
+		require.False(t, snapshot[1].Muted)
+		require.Len(t, p.Tracks, 2)
+		require.True(t, p.Tracks[0].Muted)
+	})
+}
+
+func TestFitInBudget(t *testing.T) {
+	small := &livekit.ParticipantInfo{Identity: "a"}
+	large := &livekit.ParticipantInfo{Identity: "a very long identity"}
+	size := EstimatedWireSize(small)
+
+	fit, rest := FitInBudget([]*livekit.ParticipantInfo{small, large, small, small}, 2*size)
+	require.Equal(t, []*livekit.ParticipantInfo{small, small}, fit)
+	require.Equal(t, []*livekit.ParticipantInfo{large, small}, rest)
+
+	fit, rest = FitInBudget(nil, 100)
+	require.Empty(t, fit)
+	require.Empty(t, rest)
+}
+
+func TestParticipantFirstTrack(t *testing.T) {
+	require.Nil(t, ParticipantFirstTrack(nil))
+	require.Nil(t, ParticipantFirstTrack(&livekit.ParticipantInfo{}))
+
+	track := &livekit.TrackInfo{Sid: "TR_1"}
+	require.Equal(t, track, ParticipantFirstTrack(&livekit.ParticipantInfo{Tracks: []*livekit.TrackInfo{track}}))
+}
+
+func TestParticipantUnmutedTracks(t *testing.T) {
+	require.Nil(t, ParticipantUnmutedTracks(nil))
+	require.Nil(t, ParticipantUnmutedTracksOfType(nil, livekit.TrackType_VIDEO))
+
+	video := &livekit.TrackInfo{Sid: "TR_video", Type: livekit.TrackType_VIDEO}
+	mutedVideo := &livekit.TrackInfo{Sid: "TR_muted", Type: livekit.TrackType_VIDEO, Muted: true}
+	audio := &livekit.TrackInfo{Sid: "TR_audio", Type: livekit.TrackType_AUDIO}
+	p := &livekit.ParticipantInfo{Tracks: []*livekit.TrackInfo{mutedVideo, audio, video}}
+
+	require.Equal(t, []*livekit.TrackInfo{audio, video}, ParticipantUnmutedTracks(p))
+	require.Equal(t, []*livekit.TrackInfo{video}, ParticipantUnmutedTracksOfType(p, livekit.TrackType_VIDEO))
+	require.Nil(t, ParticipantUnmutedTracksOfType(p, livekit.TrackType_DATA))
+}
+
+func TestParticipantFingerprint(t *testing.T) {
+	p := &livekit.ParticipantInfo{
+		Sid:      "PA_1",
+		Identity: "alice",
+		State:    livekit.ParticipantInfo_ACTIVE,
+		JoinedAt: 1000,
+		Tracks:   []*livekit.TrackInfo{{Sid: "TR_a"}, {Sid: "TR_b"}},
+	}
+	reconnected := &livekit.ParticipantInfo{
+		Sid:      "PA_2",
+		Identity: "alice",
+		State:    livekit.ParticipantInfo_JOINED,
+		JoinedAt: 2000,
+		Tracks:   []*livekit.TrackInfo{{Sid: "TR_b", Muted: true}, {Sid: "TR_a"}},
+	}
+	require.Len(t, ParticipantFingerprint(p), 64)
+	require.Equal(t, ParticipantFingerprint(p), ParticipantFingerprint(reconnected))
+
+	other := &livekit.ParticipantInfo{Identity: "bob", Tracks: p.Tracks}
+	require.NotEqual(t, ParticipantFingerprint(p), ParticipantFingerprint(other))
+	require.NotEqual(t, ParticipantFingerprint(p), ParticipantFingerprint(&livekit.ParticipantInfo{Identity: "alice"}))
+}
+
+func TestParticipantToMap(t *testing.T) {
+	m := ParticipantToMap(&livekit.ParticipantInfo{
+		Identity:   "alice",
+		State:      livekit.ParticipantInfo_ACTIVE,
+		Permission: &livekit.ParticipantPermission{CanPublish: true},
+		Tracks:     []*livekit.TrackInfo{{Sid: "TR_a", Type: livekit.TrackType_VIDEO}},
+	})
+	require.Equal(t, map[string]interface{}{
+		"identity":   "alice",
+		"state":      "ACTIVE",
+		"permission": map[string]interface{}{"canPublish": true},
+		"tracks": []interface{}{
+			map[string]interface{}{"sid": "TR_a", "type": "VIDEO"},
+		},
+	}, m)
+}
+
+func TestParticipantMetadata(t *testing.T) {
+	type metadata struct {
+		Role  string `json:"role"`
+		Level int    `json:"level,omitempty"`
+	}
+
+	p := &livekit.ParticipantInfo{}
+	m := metadata{Role: "unchanged"}
+	require.NoError(t, ParticipantMetadataAs(p, &m))
+	require.Equal(t, metadata{Role: "unchanged"}, m)
+
+	require.NoError(t, SetParticipantMetadataFrom(p, metadata{Role: "host", Level: 2}))
+	require.Equal(t, `{"role":"host","level":2}`, p.Metadata)
+
+	m = metadata{}
+	require.NoError(t, ParticipantMetadataAs(p, &m))
+	require.Equal(t, metadata{Role: "host", Level: 2}, m)
+
+	require.NoError(t, SetParticipantMetadataFrom(p, nil))
+	require.Empty(t, p.Metadata)
+
+	require.Error(t, SetParticipantMetadataFrom(p, func() {}))
+	p.Metadata = "not json"
+	require.Error(t, ParticipantMetadataAs(p, &m))
+}
+
+func TestParticipantsEquivalent(t *testing.T) {
+	a := &livekit.ParticipantInfo{
+		Identity: "alice",
+		State:    livekit.ParticipantInfo_JOINED,
+		JoinedAt: 10,
+		Tracks:   []*livekit.TrackInfo{{Sid: "TR_a"}},
+	}
+	b := proto.Clone(a).(*livekit.ParticipantInfo)
+	b.JoinedAt = 20
+	b.State = livekit.ParticipantInfo_ACTIVE
+
+	require.False(t, ParticipantsEquivalent(a, b))
+	require.False(t, ParticipantsEquivalent(a, b, "joined_at"))
+	require.True(t, ParticipantsEquivalent(a, b, "joined_at", "state", "unknown"))
+	require.Equal(t, int64(10), a.JoinedAt, "participants are not modified")
+
+	b.Tracks[0].Muted = true
+	require.False(t, ParticipantsEquivalent(a, b, "joined_at", "state"))
+	require.True(t, ParticipantsEquivalent(a, b, "joined_at", "state", "tracks"))
+
+	require.True(t, ParticipantsEquivalent(nil, nil))
+	require.False(t, ParticipantsEquivalent(a, nil, "joined_at"))
+}
+
+func TestDedupeParticipantTracks(t *testing.T) {
+	require.NoError(t, ValidateParticipantTracks(nil))
+	require.Zero(t, DedupeParticipantTracks(nil))
+
+	p := &livekit.ParticipantInfo{
+		Identity: "a",
+		Tracks: []*livekit.TrackInfo{
+			{Sid: "TR_1"},
+			{Sid: "TR_2"},
+			{Sid: "TR_1", Muted: true},
+			{Sid: "TR_3"},
+			{Sid: "TR_2", Muted: true},
+		},
+	}
+	err := ValidateParticipantTracks(p)
+	require.ErrorIs(t, err, ErrDuplicateTrackSID)
+	require.Contains(t, err.Error(), `"TR_1" at indexes 0 and 2`)
+
+	require.Equal(t, 2, DedupeParticipantTracks(p))
+	require.NoError(t, ValidateParticipantTracks(p))
+	require.Len(t, p.Tracks, 3)
+	require.Equal(t, "TR_1", p.Tracks[0].Sid)
+	require.True(t, p.Tracks[0].Muted)
+	require.Equal(t, "TR_3", p.Tracks[1].Sid)
+	require.Equal(t, "TR_2", p.Tracks[2].Sid)
+	require.True(t, p.Tracks[2].Muted)
+
+	require.Zero(t, DedupeParticipantTracks(p))
+}
+
+func TestFindByIdentityFold(t *testing.T) {
+	require.True(t, ParticipantIdentityEqualFold(&livekit.ParticipantInfo{Identity: "Alice"}, "aLICE"))
+	require.False(t, ParticipantIdentityEqualFold(&livekit.ParticipantInfo{Identity: "Alice"}, "Alicia"))
+	require.True(t, ParticipantIdentityEqualFold(nil, ""))
+
+	upper := &livekit.ParticipantInfo{Identity: "BOB"}
+	lower := &livekit.ParticipantInfo{Identity: "bob"}
+	participants := []*livekit.ParticipantInfo{nil, upper, lower}
+	require.Equal(t, upper, FindByIdentityFold(participants, "Bob"))
+	require.Equal(t, lower, FindByIdentityFold(participants, "bob"), "exact match preferred")
+	require.Nil(t, FindByIdentityFold(participants, "carol"))
+	require.Nil(t, FindByIdentityFold(nil, "bob"))
+}
+
+func TestParticipantPermission(t *testing.T) {
+	require.False(t, ParticipantCanPublish(nil))
+	require.False(t, ParticipantCanSubscribe(&livekit.ParticipantInfo{}))
+
+	p := &livekit.ParticipantInfo{}
+	perm := &livekit.ParticipantPermission{CanPublish: true, CanSubscribe: true}
+	SetParticipantPermission(p, perm)
+	require.True(t, ParticipantCanPublish(p))
+	require.True(t, ParticipantCanSubscribe(p))
+	require.False(t, ParticipantCanPublishData(p))
+
+	perm.CanPublish = false
+	require.True(t, ParticipantCanPublish(p), "permission is copied")
+
+	SetParticipantPermission(p, nil)
+	require.Nil(t, p.Permission)
+	require.False(t, ParticipantCanSubscribe(p))
+}
@@ -0,0 +1,140 @@
+package media
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+var errArchiveClosed = errors.New("archive is closed")
+
+// ArchiveWriter writes several track files into a single zip archive. Zip entries cannot be
+// seeked, so every entry is written to a temporary file first, allowing writers such as
+// ivfwriter.IVFWriter to update their header on Close. An entry is added to the archive
+// when it is closed.
+type ArchiveWriter struct {
+	lock    sync.Mutex
+	zw      *zip.Writer
+	entries []*ArchiveEntry
+	closed  bool
+}
+
+// NewArchiveWriter creates an ArchiveWriter writing a zip archive to out
+func NewArchiveWriter(out io.Writer) *ArchiveWriter {
+	return &ArchiveWriter{
+		zw: zip.NewWriter(out),
+	}
+}
+
+// Create returns the writer of a new entry named name
+func (a *ArchiveWriter) Create(name string) (*ArchiveEntry, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return nil, errArchiveClosed
+	}
+
+	f, err := os.CreateTemp("", "archive-entry-*")
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &ArchiveEntry{
+		archive: a,
+		name:    name,
+		file:    f,
+	}
+	a.entries = append(a.entries, entry)
+	return entry, nil
+}
+
+// Close adds the entries which were not closed yet, and finalizes the archive
+func (a *ArchiveWriter) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	var err error
+	for _, entry := range a.entries {
+		if entryErr := a.finalize(entry); err == nil {
+			err = entryErr
+		}
+	}
+	a.entries = nil
+
+	if closeErr := a.zw.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// finalize copies the temporary file of entry into the archive, must be called with the lock held
+func (a *ArchiveWriter) finalize(entry *ArchiveEntry) error {
+	if entry.file == nil {
+		return nil
+	}
+
+	f := entry.file
+	entry.file = nil
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	w, err := a.zw.Create(entry.name)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// ArchiveEntry is a file of an ArchiveWriter, it implements io.WriteSeeker and io.Closer.
+// Entries can be written while the archive is closed by another goroutine, writes then fail
+// with os.ErrClosed once the entry was added to the archive.
+type ArchiveEntry struct {
+	archive *ArchiveWriter
+	name    string
+	file    *os.File
+}
+
+func (e *ArchiveEntry) Write(p []byte) (int, error) {
+	e.archive.lock.Lock()
+	defer e.archive.lock.Unlock()
+
+	if e.file == nil {
+		return 0, os.ErrClosed
+	}
+	return e.file.Write(p)
+}
+
+func (e *ArchiveEntry) Seek(offset int64, whence int) (int64, error) {
+	e.archive.lock.Lock()
+	defer e.archive.lock.Unlock()
+
+	if e.file == nil {
+		return 0, os.ErrClosed
+	}
+	return e.file.Seek(offset, whence)
+}
+
+// Close adds the entry to the archive
+func (e *ArchiveEntry) Close() error {
+	e.archive.lock.Lock()
+	defer e.archive.lock.Unlock()
+
+	if e.file == nil {
+		return nil
+	}
+	return e.archive.finalize(e)
+}
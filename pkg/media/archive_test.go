@@ -0,0 +1,85 @@
+package media
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+func TestArchiveWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	archive := NewArchiveWriter(buf)
+
+	entry, err := archive.Create("video.ivf")
+	require.NoError(t, err)
+	writer, err := ivfwriter.NewWith(entry)
+	require.NoError(t, err)
+	for idx := 0; idx < 3; idx++ {
+		keyframe := byte(0x01)
+		if idx == 0 {
+			keyframe = 0x00
+		}
+		require.NoError(t, writer.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{Timestamp: uint32(idx) * 3000, Marker: true},
+			Payload: []byte{0x10, keyframe, 0xff, 0xff},
+		}))
+	}
+	require.NoError(t, writer.Close())
+
+	open, err := archive.Create("notes.txt")
+	require.NoError(t, err)
+	_, err = open.Write([]byte("not closed"))
+	require.NoError(t, err)
+
+	require.NoError(t, archive.Close())
+	_, err = archive.Create("late.txt")
+	require.ErrorIs(t, err, errArchiveClosed)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+
+	require.Equal(t, "video.ivf", zr.File[0].Name)
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	video, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Len(t, video, 32+3*(12+3))
+	// frame count was updated in the header
+	require.Equal(t, byte(3), video[24])
+
+	require.Equal(t, "notes.txt", zr.File[1].Name)
+	f, err = zr.File[1].Open()
+	require.NoError(t, err)
+	notes, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, "not closed", string(notes))
+}
+
+func TestArchiveWriter_CloseWhileWriting(t *testing.T) {
+	archive := NewArchiveWriter(&bytes.Buffer{})
+	entry, err := archive.Create("video.ivf")
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		for {
+			if _, err := entry.Write([]byte("data")); err != nil {
+				done <- err
+				return
+			}
+		}
+	}()
+	require.NoError(t, archive.Close())
+	require.ErrorIs(t, <-done, os.ErrClosed)
+
+	_, err = entry.Seek(0, io.SeekStart)
+	require.ErrorIs(t, err, os.ErrClosed)
+}
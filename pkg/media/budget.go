@@ -0,0 +1,69 @@
+package media
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+// ErrBudgetExceeded is returned when registering a writer would exceed the limits of a Budget
+var ErrBudgetExceeded = errors.New("recording budget exceeded")
+
+var _ ivfwriter.Budget = (*Budget)(nil)
+
+// Budget caps the resources used by writers across recordings. Writers acquire
+// a share of the budget when they are created and release it on Close, for
+// instance with ivfwriter.WithBudget.
+type Budget struct {
+	lock             sync.Mutex
+	maxWriters       int
+	maxBufferedBytes int64
+
+	writers       int
+	bufferedBytes int64
+}
+
+// NewBudget creates a Budget allowing maxWriters concurrent writers, buffering at most
+// maxBufferedBytes in total. A zero limit is unlimited.
+func NewBudget(maxWriters int, maxBufferedBytes int64) *Budget {
+	return &Budget{
+		maxWriters:       maxWriters,
+		maxBufferedBytes: maxBufferedBytes,
+	}
+}
+
+// Acquire registers a writer that buffers up to bufferedBytes, and returns ErrBudgetExceeded
+// if this would exceed the limits
+func (b *Budget) Acquire(bufferedBytes int) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.maxWriters > 0 && b.writers+1 > b.maxWriters {
+		return ErrBudgetExceeded
+	}
+	if b.maxBufferedBytes > 0 && b.bufferedBytes+int64(bufferedBytes) > b.maxBufferedBytes {
+		return ErrBudgetExceeded
+	}
+
+	b.writers++
+	b.bufferedBytes += int64(bufferedBytes)
+	return nil
+}
+
+// Release unregisters a writer, bufferedBytes must be the value it was acquired with
+func (b *Budget) Release(bufferedBytes int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.writers--
+	b.bufferedBytes -= int64(bufferedBytes)
+}
+
+// Usage returns the number of registered writers and the bytes they can buffer
+func (b *Budget) Usage() (writers int, bufferedBytes int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.writers, b.bufferedBytes
+}
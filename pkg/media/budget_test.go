@@ -0,0 +1,29 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget(t *testing.T) {
+	t.Run("max writers", func(t *testing.T) {
+		b := NewBudget(2, 0)
+		require.NoError(t, b.Acquire(1000))
+		require.NoError(t, b.Acquire(1000))
+		require.ErrorIs(t, b.Acquire(0), ErrBudgetExceeded)
+
+		b.Release(1000)
+		require.NoError(t, b.Acquire(0))
+		writers, buffered := b.Usage()
+		require.Equal(t, 2, writers)
+		require.Equal(t, int64(1000), buffered)
+	})
+
+	t.Run("max buffered bytes", func(t *testing.T) {
+		b := NewBudget(0, 1500)
+		require.NoError(t, b.Acquire(1000))
+		require.ErrorIs(t, b.Acquire(1000), ErrBudgetExceeded)
+		require.NoError(t, b.Acquire(500))
+	})
+}
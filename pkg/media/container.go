@@ -0,0 +1,74 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Container formats returned by DetectContainer
+const (
+	ContainerIVF      = "ivf"
+	ContainerOGG      = "ogg"
+	ContainerWebM     = "webm"
+	ContainerMatroska = "matroska"
+	ContainerMP4      = "mp4"
+)
+
+// containerSniffSize is enough to find the EBML DocType of WebM files written by common muxers
+const containerSniffSize = 64
+
+// ErrUnknownContainer is returned by DetectContainer when the format is not recognized
+var ErrUnknownContainer = errors.New("unknown container format")
+
+// Peeker is implemented by readers that can return upcoming bytes without consuming them, such as bufio.Reader
+type Peeker interface {
+	Peek(n int) ([]byte, error)
+}
+
+// DetectContainer identifies the container format of r from its first bytes, and returns one of
+// the Container constants. Nothing is consumed when r implements Peeker, or when it implements
+// io.Seeker, in which case it is seeked back to where it was. Other readers should be wrapped in
+// a bufio.Reader to keep the sniffed bytes.
+func DetectContainer(r io.Reader) (format string, err error) {
+	var head []byte
+	switch reader := r.(type) {
+	case Peeker:
+		head, err = reader.Peek(containerSniffSize)
+	case io.Seeker:
+		head, err = readHead(r)
+		if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+			// short inputs are read to the end, they are seeked back as well
+			if _, seekErr := reader.Seek(-int64(len(head)), io.SeekCurrent); seekErr != nil {
+				return "", seekErr
+			}
+		}
+	default:
+		head, err = readHead(r)
+	}
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	switch {
+	case bytes.HasPrefix(head, []byte("DKIF")):
+		return ContainerIVF, nil
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return ContainerOGG, nil
+	case bytes.HasPrefix(head, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		// the EBML header has a DocType element, 0x4282, containing "webm" or "matroska"
+		if bytes.Contains(head, []byte{0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'}) {
+			return ContainerWebM, nil
+		}
+		return ContainerMatroska, nil
+	case len(head) >= 8 && bytes.Equal(head[4:8], []byte("ftyp")):
+		return ContainerMP4, nil
+	}
+	return "", ErrUnknownContainer
+}
+
+func readHead(r io.Reader) ([]byte, error) {
+	head := make([]byte, containerSniffSize)
+	n, err := io.ReadFull(r, head)
+	return head[:n], err
+}
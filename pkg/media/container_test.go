@@ -0,0 +1,84 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectContainer(t *testing.T) {
+	webm := append([]byte{0x1a, 0x45, 0xdf, 0xa3, 0x9f, 0x42, 0x86, 0x81, 0x01, 0x42, 0x82, 0x84}, "webm"...)
+	mkv := append([]byte{0x1a, 0x45, 0xdf, 0xa3, 0xa3, 0x42, 0x86, 0x81, 0x01, 0x42, 0x82, 0x88}, "matroska"...)
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x20}, "ftypisom"...)
+
+	for _, tc := range []struct {
+		data   []byte
+		format string
+	}{
+		{data: []byte("DKIF\x00\x00\x20\x00VP80"), format: ContainerIVF},
+		{data: []byte("OggS\x00\x02"), format: ContainerOGG},
+		{data: webm, format: ContainerWebM},
+		{data: mkv, format: ContainerMatroska},
+		{data: mp4, format: ContainerMP4},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			format, err := DetectContainer(bytes.NewReader(tc.data))
+			require.NoError(t, err)
+			require.Equal(t, tc.format, format)
+		})
+	}
+
+	t.Run("not consumed", func(t *testing.T) {
+		data := append(mp4, make([]byte, 100)...)
+
+		seeker := bytes.NewReader(data)
+		_, err := DetectContainer(seeker)
+		require.NoError(t, err)
+		read, err := ioutil.ReadAll(seeker)
+		require.NoError(t, err)
+		require.Equal(t, data, read)
+
+		peeker := bufio.NewReader(struct{ io.Reader }{bytes.NewReader(data)})
+		_, err = DetectContainer(peeker)
+		require.NoError(t, err)
+		read, err = ioutil.ReadAll(peeker)
+		require.NoError(t, err)
+		require.Equal(t, data, read)
+	})
+
+	t.Run("short file not consumed", func(t *testing.T) {
+		// an IVF file with only its header
+		data := append([]byte("DKIF"), make([]byte, 28)...)
+		seeker := bytes.NewReader(data)
+		format, err := DetectContainer(seeker)
+		require.NoError(t, err)
+		require.Equal(t, ContainerIVF, format)
+		read, err := ioutil.ReadAll(seeker)
+		require.NoError(t, err)
+		require.Equal(t, data, read)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := DetectContainer(bytes.NewReader([]byte("RIFF")))
+		require.ErrorIs(t, err, ErrUnknownContainer)
+		_, err = DetectContainer(bytes.NewReader(nil))
+		require.ErrorIs(t, err, ErrUnknownContainer)
+
+		readErr := errors.New("read error")
+		_, err = DetectContainer(&failingReader{err: readErr})
+		require.ErrorIs(t, err, readErr)
+	})
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,37 @@
+package media
+
+import (
+	"errors"
+	"io"
+
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
+)
+
+// ErrNotDecodable is returned by FirstDecodablePoint when the file has no key frame
+var ErrNotDecodable = errors.New("no key frame found")
+
+// FirstDecodablePoint returns the byte offset and the PTS of the first key frame of an IVF file,
+// from which clients can start decoding. Only VP8 and AV1 files have detectable key frames, for
+// AV1 this is the first sequence header. ErrNotDecodable is returned when the file ends before
+// a key frame.
+func FirstDecodablePoint(r io.Reader) (offset int64, pts uint64, err error) {
+	reader, header, err := ivfreader.NewWith(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset = ivfFileHeaderSize
+	for {
+		frame, frameHeader, err := reader.ParseNextFrame()
+		if err == io.EOF {
+			return 0, 0, ErrNotDecodable
+		} else if err != nil {
+			return 0, 0, err
+		}
+
+		if isIVFKeyFrame(header.FourCC, frame) {
+			return offset, frameHeader.Timestamp, nil
+		}
+		offset += ivfFrameHeaderSize + int64(len(frame))
+	}
+}
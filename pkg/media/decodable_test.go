@@ -0,0 +1,34 @@
+package media
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+func TestFirstDecodablePoint(t *testing.T) {
+	out := &seekBuffer{}
+	writer, err := ivfwriter.NewWith(out)
+	require.NoError(t, err)
+	// an inter frame flagged as a key frame, writing it is allowed but clients cannot start there
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0x02, 0x03}, true, 0))
+	require.NoError(t, writer.WriteFrame([]byte{0x00, 0x02}, true, 1))
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0x02}, false, 2))
+	require.NoError(t, writer.Close())
+
+	offset, pts, err := FirstDecodablePoint(bytes.NewReader(out.buf))
+	require.NoError(t, err)
+	require.Equal(t, int64(32+12+3), offset)
+	require.Equal(t, uint64(1), pts)
+
+	writerOffset, writerPTS, ok := writer.FirstDecodablePoint()
+	require.True(t, ok)
+	require.Equal(t, offset, writerOffset)
+	require.Equal(t, pts, writerPTS)
+
+	_, _, err = FirstDecodablePoint(bytes.NewReader(out.buf[:32+12+3]))
+	require.ErrorIs(t, err, ErrNotDecodable)
+}
@@ -0,0 +1,50 @@
+package ivfwriter
+
+// Anomaly is a reason for WriteRTP to skip a packet without returning an error
+type Anomaly int
+
+const (
+	// AnomalyEmptyPayload is a packet without payload
+	AnomalyEmptyPayload Anomaly = iota
+	// AnomalyBeforeKeyFrame is a VP8 packet received before the first key frame
+	AnomalyBeforeKeyFrame
+	// AnomalyMidFrameStart is a packet continuing a frame whose first packet was not received
+	AnomalyMidFrameStart
+	// AnomalyLossPaused is a packet received while writing is paused by WithLossThreshold
+	AnomalyLossPaused
+	// AnomalyAV1Resync is an AV1 packet dropped until the next coded video sequence, after the
+	// buffer limited by WithMaxAV1BufferSize overflowed
+	AnomalyAV1Resync
+)
+
+func (a Anomaly) String() string {
+	switch a {
+	case AnomalyEmptyPayload:
+		return "empty payload"
+	case AnomalyBeforeKeyFrame:
+		return "before key frame"
+	case AnomalyMidFrameStart:
+		return "mid-frame start"
+	case AnomalyLossPaused:
+		return "paused on loss"
+	case AnomalyAV1Resync:
+		return "AV1 resync"
+	}
+	return "unknown"
+}
+
+// WithErrorCallback sets a callback invoked from WriteRTP when a packet is skipped for one of the
+// reasons enumerated by Anomaly. WriteRTP still returns nil for those packets.
+func WithErrorCallback(cb func(anomaly Anomaly)) Option {
+	return func(i *IVFWriter) error {
+		i.onAnomaly = cb
+		return nil
+	}
+}
+
+// skip reports a packet skipped because of anomaly
+func (i *IVFWriter) skip(anomaly Anomaly) {
+	if i.onAnomaly != nil {
+		i.onAnomaly(anomaly)
+	}
+}
@@ -0,0 +1,30 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_ErrorCallback(t *testing.T) {
+	var anomalies []Anomaly
+	writer, err := NewWith(&bytes.Buffer{}, WithErrorCallback(func(anomaly Anomaly) {
+		anomalies = append(anomalies, anomaly)
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(&rtp.Packet{}))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(1000, false)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(2000, true)))
+
+	continuation := newVP8Packet(3000, false)
+	continuation.Payload[0] = 0x00
+	require.NoError(t, writer.WriteRTP(continuation))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(4000, false)))
+
+	require.Equal(t, []Anomaly{AnomalyEmptyPayload, AnomalyBeforeKeyFrame, AnomalyMidFrameStart}, anomalies)
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+	require.Equal(t, "mid-frame start", AnomalyMidFrameStart.String())
+}
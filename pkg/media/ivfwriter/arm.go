@@ -0,0 +1,36 @@
+package ivfwriter
+
+// Disarm stops writing frames, until Arm is called. Packets are still reassembled, so that
+// writing can resume at the next key frame. Unlike Cut, the frames written once armed again
+// continue the same file: their PTS follow the last frame written, and the time spent disarmed
+// is not part of the recording's duration.
+func (i *IVFWriter) Disarm() {
+	i.disarmed = true
+	i.armPending = false
+}
+
+// Arm resumes writing frames after Disarm, starting at the next key frame. Streams of custom
+// codecs resume at the next frame, since their key frames cannot be detected.
+func (i *IVFWriter) Arm() {
+	if !i.disarmed {
+		return
+	}
+	i.disarmed = false
+	i.armPending = true
+}
+
+// resumeArmed returns true if frame can start writing after Arm
+func (i *IVFWriter) resumeArmed(frame []byte) bool {
+	if (i.isVP8 || i.isAV1) && !i.isKeyFrame(frame) {
+		return false
+	}
+	i.armPending = false
+	i.logger.Debugf("armed, resuming at frame %d", i.frameCount)
+
+	if i.bytes > 0 {
+		// the time spent disarmed is excluded, like the time before a clock rate change
+		i.clockRateDuration = i.writtenDuration()
+		i.setFirstTimestamp(i.frameTimestamp)
+	}
+	return true
+}
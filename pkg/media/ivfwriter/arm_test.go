@@ -0,0 +1,45 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_Arm(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithFrameStats())
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+
+	writer.Disarm()
+	require.NoError(t, writer.WriteRTP(newVP8Packet(6000, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(9000, false)))
+
+	writer.Arm()
+	require.NoError(t, writer.WriteRTP(newVP8Packet(90000, false)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(93000, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(96000, false)))
+	require.NoError(t, writer.Close())
+
+	var timestamps []uint32
+	for _, stat := range writer.FrameStats() {
+		timestamps = append(timestamps, stat.RTPTimestamp)
+	}
+	require.Equal(t, []uint32{0, 3000, 93000, 96000}, timestamps)
+
+	stats := writer.Stats()
+	require.Equal(t, uint64(4), stats.FrameCount)
+	require.Equal(t, 2*time.Second/30, stats.Duration, "time spent disarmed is excluded")
+
+	// arming a writer that is not disarmed has no effect
+	writer, err = NewWith(&bytes.Buffer{})
+	require.NoError(t, err)
+	writer.Arm()
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+}
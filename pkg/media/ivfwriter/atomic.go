@@ -0,0 +1,59 @@
+package ivfwriter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var errAtomicFinalizeOutput = errors.New("atomic finalize requires a file output, without segments")
+
+// WithAtomicFinalize writes the recording to the file the writer was created with, as a temporary
+// file, and moves it to finalPath on Close, so that readers of finalPath never see a partial file.
+// Close updates the header, flushes the file to stable storage, closes it and renames it to
+// finalPath, replacing any file there. When the recording cannot be completed, the temporary file
+// is removed instead. finalPath must be on the same file system as the temporary file. It requires
+// an *os.File output, such as the one of New, and cannot be used with WithSegments.
+func WithAtomicFinalize(finalPath string) Option {
+	return func(i *IVFWriter) error {
+		i.finalPath = finalPath
+		return nil
+	}
+}
+
+func (i *IVFWriter) checkAtomicFinalize() error {
+	if i.finalPath == "" {
+		return nil
+	}
+	if _, ok := i.ioWriter.(*os.File); !ok || i.openSegment != nil {
+		return errAtomicFinalizeOutput
+	}
+	return nil
+}
+
+// finalizeFile syncs and closes the temporary file, then renames it unless err is set or any step
+// fails, in which case it is removed
+func (i *IVFWriter) finalizeFile(f *os.File, err error) error {
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), i.finalPath)
+	}
+	if err != nil {
+		if removeErr := os.Remove(f.Name()); removeErr != nil {
+			i.logger.Warnf("could not remove %s: %v", f.Name(), removeErr)
+		}
+		return err
+	}
+
+	// persist the rename, this is not supported on all platforms so errors are ignored
+	if dir, dirErr := os.Open(filepath.Dir(i.finalPath)); dirErr == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_AtomicFinalize(t *testing.T) {
+	dir := t.TempDir()
+	tmpPath, finalPath := filepath.Join(dir, "out.ivf.tmp"), filepath.Join(dir, "out.ivf")
+
+	writer, err := New(tmpPath, WithAtomicFinalize(finalPath))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	_, err = os.Stat(finalPath)
+	require.True(t, os.IsNotExist(err), "not visible until closed")
+
+	require.NoError(t, writer.Close())
+	_, err = os.Stat(tmpPath)
+	require.True(t, os.IsNotExist(err))
+	data, err := ioutil.ReadFile(finalPath)
+	require.NoError(t, err)
+	require.Len(t, data, 32+2*(12+3))
+	require.Equal(t, uint32(2), binary.LittleEndian.Uint32(data[24:]), "header updated before the rename")
+}
+
+func TestIVFWriter_AtomicFinalizeFailure(t *testing.T) {
+	dir := t.TempDir()
+	tmpPath, finalPath := filepath.Join(dir, "out.ivf.tmp"), filepath.Join(dir, "out.ivf")
+
+	f, err := os.Create(tmpPath)
+	require.NoError(t, err)
+	writer, err := NewWith(f, WithAtomicFinalize(finalPath))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+
+	// the header cannot be updated
+	require.NoError(t, f.Close())
+	require.Error(t, writer.Close())
+	_, err = os.Stat(tmpPath)
+	require.True(t, os.IsNotExist(err), "temporary file removed")
+	_, err = os.Stat(finalPath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestIVFWriter_AtomicFinalizeOutput(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithAtomicFinalize("out.ivf"))
+	require.ErrorIs(t, err, errAtomicFinalizeOutput)
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.ivf.tmp"))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = NewWith(f, WithAtomicFinalize("out.ivf"), WithSegments(func(int) (io.Writer, error) {
+		return &bytes.Buffer{}, nil
+	}))
+	require.ErrorIs(t, err, errAtomicFinalizeOutput)
+}
@@ -0,0 +1,64 @@
+package ivfwriter
+
+import (
+	"errors"
+
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/rtp/pkg/frame"
+)
+
+var errInvalidAV1BufferSize = errors.New("AV1 buffer size must be positive")
+
+// WithMaxAV1BufferSize bounds the size of a fragmented AV1 OBU being reassembled. When a stream
+// exceeds it, such as one that never completes an OBU, the fragments are discarded and writing
+// resumes at the next key frame, detected with the N bit of the aggregation header.
+func WithMaxAV1BufferSize(size int) Option {
+	return func(i *IVFWriter) error {
+		if size <= 0 {
+			return errInvalidAV1BufferSize
+		}
+		i.maxAV1BufferSize = size
+		return nil
+	}
+}
+
+// exceedsAV1BufferSize returns true if the packet was discarded because the fragment
+// buffered after it would exceed the maximum size
+func (i *IVFWriter) exceedsAV1BufferSize(packet *codecs.AV1Packet) bool {
+	if i.maxAV1BufferSize == 0 {
+		return false
+	}
+
+	buffered := av1BufferedAfter(i.av1Buffered, packet)
+	if buffered <= i.maxAV1BufferSize {
+		i.av1Buffered = buffered
+		return false
+	}
+
+	i.logger.Warnf("AV1 fragment larger than %d bytes, discarding until the next key frame", i.maxAV1BufferSize)
+	i.av1Frame = frame.AV1{}
+	i.av1Buffered = 0
+	i.av1Resyncing = true
+	i.fragmentCount = 0
+	return true
+}
+
+// av1BufferedAfter returns the size of the fragment frame.AV1 buffers after reading packet,
+// buffered being the size before. It mirrors frame.AV1.ReadFrames, which keeps the last OBU
+// element when it continues in the next packet, appended to the buffer if it is a continuation.
+func av1BufferedAfter(buffered int, packet *codecs.AV1Packet) int {
+	count := len(packet.OBUElements)
+	if !packet.Y || count == 0 {
+		return 0
+	}
+
+	last := len(packet.OBUElements[count-1])
+	if count == 1 && packet.Z {
+		if buffered == 0 {
+			// a continuation without a first fragment is discarded
+			return 0
+		}
+		return buffered + last
+	}
+	return last
+}
@@ -0,0 +1,63 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+// newAV1Packet returns a packet with a single OBU element, z and y mark a fragment
+// continuing the previous packet and continued in the next one
+func newAV1Packet(timestamp uint32, z, y, n bool, size int) *rtp.Packet {
+	header := byte(0x10) // W=1, one element without length
+	if z {
+		header |= 0x80
+	}
+	if y {
+		header |= 0x40
+	}
+	if n {
+		header |= 0x08
+	}
+	payload := make([]byte, 1+size)
+	payload[0] = header
+	payload[1] = 0x32 // OBU_FRAME with size field
+	return &rtp.Packet{
+		Header:  rtp.Header{Timestamp: timestamp, Marker: !y},
+		Payload: payload,
+	}
+}
+
+func TestIVFWriter_MaxAV1BufferSize(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithMaxAV1BufferSize(0))
+	require.ErrorIs(t, err, errInvalidAV1BufferSize)
+
+	writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithMaxAV1BufferSize(250))
+	require.NoError(t, err)
+
+	// a complete OBU fragmented in two packets fits
+	require.NoError(t, writer.WriteRTP(newAV1Packet(0, false, true, true, 100)))
+	require.NoError(t, writer.WriteRTP(newAV1Packet(0, true, false, false, 100)))
+	require.Equal(t, uint64(1), writer.Stats().FrameCount)
+	require.Equal(t, 0, writer.av1Buffered)
+
+	// an OBU that is never completed is discarded
+	require.NoError(t, writer.WriteRTP(newAV1Packet(3000, false, true, false, 100)))
+	require.NoError(t, writer.WriteRTP(newAV1Packet(3000, true, true, false, 100)))
+	require.Equal(t, 200, writer.av1Buffered)
+	require.NoError(t, writer.WriteRTP(newAV1Packet(3000, true, true, false, 100)))
+	require.True(t, writer.av1Resyncing)
+	require.Equal(t, 0, writer.av1Buffered)
+
+	// until the next key frame
+	require.NoError(t, writer.WriteRTP(newAV1Packet(3000, true, false, false, 100)))
+	require.NoError(t, writer.WriteRTP(newAV1Packet(6000, false, false, false, 100)))
+	require.Equal(t, uint64(1), writer.Stats().FrameCount)
+
+	require.NoError(t, writer.WriteRTP(newAV1Packet(9000, false, false, true, 100)))
+	require.False(t, writer.av1Resyncing)
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+	require.NoError(t, writer.Close())
+}
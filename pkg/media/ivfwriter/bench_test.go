@@ -0,0 +1,145 @@
+package ivfwriter
+
+// The benchmarks measure the throughput of WriteRTP for typical frame sizes, each iteration
+// writing one frame fragmented in packets of benchPacketSize bytes. Run them with:
+//
+//	go test -run '^$' -bench . -benchmem ./pkg/media/ivfwriter
+//
+// To profile a benchmark, write the CPU and memory profiles and open them with pprof:
+//
+//	go test -run '^$' -bench 'VP8/4K' -cpuprofile cpu.out -memprofile mem.out ./pkg/media/ivfwriter
+//	go tool pprof -http :8080 cpu.out
+//
+// Compare runs with benchstat before and after a change, using -count to get several samples.
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	benchPacketSize = 1200
+	benchGOPSize    = 60
+)
+
+// benchFrameSizes are typical sizes of inter frames, key frames are benchKeyFrameFactor times larger
+var benchFrameSizes = []struct {
+	name string
+	size int
+}{
+	{"720p", 12 * 1024},
+	{"1080p", 25 * 1024},
+	{"4K", 100 * 1024},
+}
+
+const benchKeyFrameFactor = 4
+
+// vp8BenchFrame returns the packets of a VP8 frame of size bytes
+func vp8BenchFrame(size int, keyframe bool) []*rtp.Packet {
+	var packets []*rtp.Packet
+	for offset := 0; offset < size; offset += benchPacketSize {
+		n := size - offset
+		if n > benchPacketSize {
+			n = benchPacketSize
+		}
+		payload := make([]byte, 1+n)
+		if offset == 0 {
+			payload[0] = 0x10
+			if !keyframe {
+				payload[1] = 0x01
+			}
+		}
+		packets = append(packets, &rtp.Packet{Payload: payload})
+	}
+	packets[len(packets)-1].Marker = true
+	return packets
+}
+
+// av1BenchFrame returns the packets of an AV1 frame of size bytes, made of a single fragmented OBU
+func av1BenchFrame(size int) []*rtp.Packet {
+	var packets []*rtp.Packet
+	for offset := 0; offset < size; offset += benchPacketSize {
+		n := size - offset
+		if n > benchPacketSize {
+			n = benchPacketSize
+		}
+		packets = append(packets, newAV1Packet(0, offset > 0, offset+n < size, false, n))
+	}
+	return packets
+}
+
+func benchmarkWriter(b *testing.B, frameSize int, opts []Option, frame func(keyframe bool) []*rtp.Packet) {
+	writer, err := NewWith(io.Discard, opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	keyFrame, interFrame := frame(true), frame(false)
+
+	b.SetBytes(int64(frameSize))
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		packets := interFrame
+		if i%benchGOPSize == 0 {
+			packets = keyFrame
+		}
+		for _, packet := range packets {
+			packet.Timestamp = uint32(i) * 3000
+			if err := writer.WriteRTP(packet); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "frames/s")
+}
+
+func BenchmarkIVFWriter_VP8(b *testing.B) {
+	for _, fs := range benchFrameSizes {
+		size := fs.size
+		b.Run(fs.name, func(b *testing.B) {
+			benchmarkWriter(b, size, nil, func(keyframe bool) []*rtp.Packet {
+				if keyframe {
+					return vp8BenchFrame(benchKeyFrameFactor*size, true)
+				}
+				return vp8BenchFrame(size, false)
+			})
+		})
+	}
+}
+
+func BenchmarkIVFWriter_AV1(b *testing.B) {
+	for _, fs := range benchFrameSizes {
+		size := fs.size
+		b.Run(fs.name, func(b *testing.B) {
+			benchmarkWriter(b, size, []Option{WithCodec(mimeTypeAV1)}, func(keyframe bool) []*rtp.Packet {
+				if keyframe {
+					return av1BenchFrame(benchKeyFrameFactor * size)
+				}
+				return av1BenchFrame(size)
+			})
+		})
+	}
+}
+
+// BenchmarkIVFWriter_Async measures the ingestion path of WithOverflowPolicy, where frames are queued
+// to the goroutine writing the output
+func BenchmarkIVFWriter_Async(b *testing.B) {
+	for _, fs := range benchFrameSizes {
+		size := fs.size
+		b.Run(fs.name, func(b *testing.B) {
+			benchmarkWriter(b, size, []Option{WithOverflowPolicy(OverflowBlock, 64)}, func(keyframe bool) []*rtp.Packet {
+				if keyframe {
+					return vp8BenchFrame(benchKeyFrameFactor*size, true)
+				}
+				return vp8BenchFrame(size, false)
+			})
+		})
+	}
+}
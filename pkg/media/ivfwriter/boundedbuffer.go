@@ -0,0 +1,138 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+const (
+	ivfFileHeaderSize  = 32
+	ivfFrameHeaderSize = 12
+)
+
+var errInvalidBufferSize = errors.New("buffer must be larger than the IVF header")
+
+// BoundedBuffer is an IVFWriter keeping its output in memory, bounded to the last maxBytes.
+// Frames are evicted from the front at key frames, so that the retained output always starts
+// with the IVF header followed by a key frame. When the frames since the last key frame alone
+// exceed the bound, they are all evicted and the next frames are discarded until a key frame.
+// Frames of custom codecs are all considered key frames.
+type BoundedBuffer struct {
+	*IVFWriter
+	out *boundedOutput
+}
+
+// NewBoundedBuffer builds an IVFWriter whose output is retained in memory, see BoundedBuffer.
+// maxBytes includes the IVF header.
+func NewBoundedBuffer(maxBytes int, opts ...Option) (*BoundedBuffer, error) {
+	if maxBytes <= ivfFileHeaderSize {
+		return nil, errInvalidBufferSize
+	}
+
+	out := &boundedOutput{maxBytes: maxBytes}
+	writer, err := NewWith(out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out.isKeyFrame = func(frame []byte) bool {
+		return writer.isKeyFrame(frame) || !writer.isVP8 && !writer.isAV1
+	}
+	return &BoundedBuffer{IVFWriter: writer, out: out}, nil
+}
+
+// Snapshot returns a copy of the retained output, it is empty until the IVF header is written
+func (b *BoundedBuffer) Snapshot() []byte {
+	return b.out.snapshot()
+}
+
+// boundedOutput parses the IVF stream written by the IVFWriter into frames
+type boundedOutput struct {
+	lock       sync.Mutex
+	maxBytes   int
+	isKeyFrame func(frame []byte) bool
+
+	header  []byte
+	pending []byte
+	// retained frames including their headers, and offsets of their key frames
+	frames       []byte
+	keyFrames    []int
+	waitKeyFrame bool
+}
+
+func (o *boundedOutput) Write(p []byte) (int, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.pending = append(o.pending, p...)
+	if o.header == nil {
+		if len(o.pending) < ivfFileHeaderSize {
+			return len(p), nil
+		}
+		o.header = append([]byte{}, o.pending[:ivfFileHeaderSize]...)
+		o.pending = o.pending[ivfFileHeaderSize:]
+	}
+
+	for len(o.pending) >= ivfFrameHeaderSize {
+		size := ivfFrameHeaderSize + int(binary.LittleEndian.Uint32(o.pending))
+		if len(o.pending) < size {
+			break
+		}
+		o.addFrame(o.pending[:size])
+		o.pending = o.pending[size:]
+	}
+	o.pending = append([]byte{}, o.pending...)
+	return len(p), nil
+}
+
+func (o *boundedOutput) addFrame(frame []byte) {
+	keyFrame := o.isKeyFrame(frame[ivfFrameHeaderSize:])
+	if o.waitKeyFrame && !keyFrame {
+		return
+	}
+	o.waitKeyFrame = false
+
+	if keyFrame {
+		o.keyFrames = append(o.keyFrames, len(o.frames))
+	}
+	o.frames = append(o.frames, frame...)
+
+	limit := o.maxBytes - len(o.header)
+	if len(o.frames) <= limit {
+		return
+	}
+
+	// evict up to the first key frame leaving the rest within the bound
+	for j, offset := range o.keyFrames {
+		if len(o.frames)-offset <= limit {
+			o.evict(j)
+			return
+		}
+	}
+	o.frames = nil
+	o.keyFrames = nil
+	o.waitKeyFrame = true
+}
+
+// evict removes the frames before the key frame at index j of keyFrames
+func (o *boundedOutput) evict(j int) {
+	offset := o.keyFrames[j]
+	o.frames = append([]byte{}, o.frames[offset:]...)
+	keyFrames := o.keyFrames[:0]
+	for _, k := range o.keyFrames[j:] {
+		keyFrames = append(keyFrames, k-offset)
+	}
+	o.keyFrames = keyFrames
+}
+
+func (o *boundedOutput) snapshot() []byte {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.header == nil {
+		return nil
+	}
+	snapshot := make([]byte, 0, len(o.header)+len(o.frames))
+	snapshot = append(snapshot, o.header...)
+	return append(snapshot, o.frames...)
+}
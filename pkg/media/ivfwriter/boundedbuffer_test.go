@@ -0,0 +1,55 @@
+package ivfwriter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundedBuffer(t *testing.T) {
+	_, err := NewBoundedBuffer(ivfFileHeaderSize)
+	require.ErrorIs(t, err, errInvalidBufferSize)
+
+	// room for the header and 3 frames of 3 bytes
+	const frameSize = ivfFrameHeaderSize + 3
+	buffer, err := NewBoundedBuffer(ivfFileHeaderSize + 3*frameSize)
+	require.NoError(t, err)
+	require.Len(t, buffer.Snapshot(), ivfFileHeaderSize)
+
+	ts := uint32(0)
+	write := func(keyframe bool) {
+		ts += 3000
+		require.NoError(t, buffer.WriteRTP(newVP8Packet(ts, keyframe)))
+	}
+	requireFrames := func(frames int) []byte {
+		snapshot := buffer.Snapshot()
+		require.Len(t, snapshot, ivfFileHeaderSize+frames*frameSize)
+		require.Equal(t, "DKIF", string(snapshot[:4]))
+		if frames > 0 {
+			require.True(t, isVP8KeyFrame(snapshot[ivfFileHeaderSize+ivfFrameHeaderSize:]), "retained frames start with a key frame")
+		}
+		return snapshot
+	}
+
+	write(true)
+	write(false)
+	write(false)
+	requireFrames(3)
+
+	write(false)
+	requireFrames(0)
+	write(false)
+	requireFrames(0)
+
+	write(true)
+	write(false)
+	write(false)
+	requireFrames(3)
+
+	write(true)
+	write(false)
+	requireFrames(2)
+
+	require.NoError(t, buffer.Close())
+	requireFrames(2)
+}
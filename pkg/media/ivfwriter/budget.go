@@ -0,0 +1,46 @@
+package ivfwriter
+
+import (
+	"errors"
+)
+
+// ErrFrameTooLarge is returned by WriteRTP when a frame larger than allowed by WithBudget was
+// dropped, the following frames are written
+var ErrFrameTooLarge = errors.New("frame exceeds the buffer size of the budget")
+
+// Budget is a resource budget shared by several writers, such as media.Budget
+type Budget interface {
+	Acquire(bufferedBytes int) error
+	Release(bufferedBytes int)
+}
+
+// WithBudget registers the writer with budget when it is created, and releases it on Close.
+// Creating the writer fails with the error of the budget if it is exhausted. The writer then
+// buffers at most maxBufferedBytes per VP8 or custom codec frame, larger frames are dropped
+// and WriteRTP returns an error.
+func WithBudget(budget Budget, maxBufferedBytes int) Option {
+	return func(i *IVFWriter) error {
+		i.budget = budget
+		i.maxBufferedBytes = maxBufferedBytes
+		return nil
+	}
+}
+
+// releaseBudget releases the budget acquired by NewWith, once
+func (i *IVFWriter) releaseBudget() {
+	if i.budget != nil {
+		i.budget.Release(i.maxBufferedBytes)
+		i.budget = nil
+	}
+}
+
+// exceedsBufferedBytes drops the frame being reassembled if it is larger than allowed by the budget
+func (i *IVFWriter) exceedsBufferedBytes() bool {
+	if i.budget == nil || i.maxBufferedBytes <= 0 || len(i.currentFrame) <= i.maxBufferedBytes {
+		return false
+	}
+
+	i.logger.Warnf("dropping frame larger than %d bytes", i.maxBufferedBytes)
+	i.currentFrame = nil
+	return true
+}
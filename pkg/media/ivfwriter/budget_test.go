@@ -0,0 +1,65 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+type testBudget struct {
+	acquired int
+	err      error
+}
+
+func (b *testBudget) Acquire(bufferedBytes int) error {
+	if b.err != nil {
+		return b.err
+	}
+	b.acquired += bufferedBytes
+	return nil
+}
+
+func (b *testBudget) Release(bufferedBytes int) {
+	b.acquired -= bufferedBytes
+}
+
+func TestIVFWriter_Budget(t *testing.T) {
+	budgetErr := errors.New("exceeded")
+	_, err := NewWith(&bytes.Buffer{}, WithBudget(&testBudget{err: budgetErr}, 10))
+	require.ErrorIs(t, err, budgetErr)
+
+	budget := &testBudget{}
+	writer, err := NewWith(&bytes.Buffer{}, WithBudget(budget, 4))
+	require.NoError(t, err)
+	require.Equal(t, 4, budget.acquired)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+
+	// 6 bytes frame
+	packet := &rtp.Packet{
+		Header:  rtp.Header{Timestamp: 3000, Marker: true},
+		Payload: []byte{0x10, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	require.ErrorIs(t, writer.WriteRTP(packet), ErrFrameTooLarge)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(6000, false)))
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+
+	require.NoError(t, writer.Close())
+	require.NoError(t, writer.Close())
+	require.Equal(t, 0, budget.acquired)
+}
+
+func TestIVFWriter_BudgetNewRemovesFile(t *testing.T) {
+	budgetErr := errors.New("exceeded")
+	fileName := filepath.Join(t.TempDir(), "out.ivf")
+	_, err := New(fileName, WithBudget(&testBudget{err: budgetErr}, 10))
+	require.ErrorIs(t, err, budgetErr)
+
+	_, err = os.Stat(fileName)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,66 @@
+package ivfwriter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+type chapter struct {
+	pts   uint64
+	label string
+}
+
+// AddChapter marks a moment of the recording, pts is expressed in frames, like the PTS
+// of the written frames. Chapters are written to the sidecar set with WithChapterSidecar on Close.
+func (i *IVFWriter) AddChapter(pts uint64, label string) {
+	i.chapters = append(i.chapters, chapter{pts: pts, label: label})
+}
+
+// writeChapters writes chapters in the FFMETADATA format, each chapter ends where the next
+// one starts, the last one ends with the recording. The time base is the one of the header.
+func (i *IVFWriter) writeChapters() error {
+	sort.SliceStable(i.chapters, func(a, b int) bool {
+		return i.chapters[a].pts < i.chapters[b].pts
+	})
+
+	var sb strings.Builder
+	sb.WriteString(";FFMETADATA1\n")
+	for j, c := range i.chapters {
+		end := i.frameCount
+		if j+1 < len(i.chapters) {
+			end = i.chapters[j+1].pts
+		}
+		if end < c.pts {
+			end = c.pts
+		}
+
+		fmt.Fprintf(&sb, "[CHAPTER]\nTIMEBASE=%d/%d\nSTART=%d\nEND=%d\ntitle=%s\n",
+			i.frameRateDen, i.frameRateNum, c.pts, end, escapeMetadata(c.label))
+	}
+
+	_, err := io.WriteString(i.chapterSidecar, sb.String())
+	return err
+}
+
+var metadataEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"=", `\=`,
+	";", `\;`,
+	"#", `\#`,
+	"\n", "\\\n",
+)
+
+func escapeMetadata(s string) string {
+	return metadataEscaper.Replace(s)
+}
+
+// WithChapterSidecar writes the chapters added with AddChapter to out on Close, in the
+// FFMETADATA format understood by ffmpeg. out is not closed by the IVFWriter.
+func WithChapterSidecar(out io.Writer) Option {
+	return func(i *IVFWriter) error {
+		i.chapterSidecar = out
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_Chapters(t *testing.T) {
+	sidecar := &bytes.Buffer{}
+	writer, err := NewWith(&bytes.Buffer{}, WithChapterSidecar(sidecar))
+	require.NoError(t, err)
+
+	for j := 0; j < 10; j++ {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(uint32(j)*3750, j == 0)))
+	}
+	writer.AddChapter(4, "question; from #2")
+	writer.AddChapter(0, "intro")
+	require.NoError(t, writer.Close())
+
+	require.Equal(t, `;FFMETADATA1
+[CHAPTER]
+TIMEBASE=1/24
+START=0
+END=4
+title=intro
+[CHAPTER]
+TIMEBASE=1/24
+START=4
+END=10
+title=question\; from \#2
+`, sidecar.String())
+}
@@ -0,0 +1,62 @@
+package ivfwriter
+
+import (
+	"hash"
+	"io"
+)
+
+// WithChecksum feeds the bytes written to the output into h, so that the output can be verified
+// without reading it again, see Checksum. h is reset for every output written with WithSegments.
+//
+// When the output can seek, Close updates the header written with provisional values, and a hash
+// cannot be updated for bytes it already consumed. The checksum of such outputs is recomputed on
+// Close from the output if it is also an io.Reader, as files are, and is nil otherwise.
+func WithChecksum(h hash.Hash) Option {
+	return func(i *IVFWriter) error {
+		i.checksum = h
+		return nil
+	}
+}
+
+// Checksum returns the sum of the hash set with WithChecksum over the bytes of the output, once it
+// is closed. With WithSegments, it is the checksum of the last segment closed.
+func (i *IVFWriter) Checksum() []byte {
+	return i.checksumSum
+}
+
+func (i *IVFWriter) hashOutput(b ...[]byte) {
+	if i.checksum == nil {
+		return
+	}
+	for _, p := range b {
+		_, _ = i.checksum.Write(p)
+	}
+}
+
+// sumOutput computes the checksum of the output once it is finished, and resets the hash for the next one
+func (i *IVFWriter) sumOutput(headerUpdated bool) error {
+	if i.checksum == nil {
+		return nil
+	}
+	defer i.checksum.Reset()
+
+	if !headerUpdated {
+		i.checksumSum = i.checksum.Sum(nil)
+		return nil
+	}
+
+	i.checksumSum = nil
+	rs, ok := i.ioWriter.(io.ReadSeeker)
+	if !ok {
+		return nil
+	}
+	i.checksum.Reset()
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(i.checksum, rs); err != nil {
+		return err
+	}
+	i.checksumSum = i.checksum.Sum(nil)
+	return nil
+}
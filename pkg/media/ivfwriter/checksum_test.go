@@ -0,0 +1,42 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_Checksum(t *testing.T) {
+	write := func(writer *IVFWriter) {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(3000, true)))
+		require.NoError(t, writer.WriteRTP(newVP8Packet(6000, false)))
+		require.Nil(t, writer.Checksum())
+		require.NoError(t, writer.Close())
+	}
+
+	t.Run("Stream", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		writer, err := NewWith(out, WithChecksum(sha256.New()))
+		require.NoError(t, err)
+		write(writer)
+
+		sum := sha256.Sum256(out.Bytes())
+		require.Equal(t, sum[:], writer.Checksum())
+	})
+
+	t.Run("HeaderUpdated", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "out.ivf")
+		writer, err := New(fileName, WithChecksum(sha256.New()))
+		require.NoError(t, err)
+		write(writer)
+
+		data, err := ioutil.ReadFile(fileName)
+		require.NoError(t, err)
+		sum := sha256.Sum256(data)
+		require.Equal(t, sum[:], writer.Checksum(), "checksum of the updated header")
+	})
+}
@@ -0,0 +1,42 @@
+package ivfwriter
+
+import "time"
+
+// CloseResult summarizes a recording once closed
+type CloseResult struct {
+	// FrameCount is the number of frames, including the dropped ones
+	FrameCount uint64
+	// Dropped is the number of frames reported with FrameDropped or dropped by the overflow policy
+	Dropped uint64
+	// Duration is the time between the first and the last frame written, from RTP timestamps
+	Duration time.Duration
+	// BytesWritten is the size of the output, including IVF headers. With WithSegments,
+	// it is the size of the last segment.
+	BytesWritten int64
+	// Width and Height are parsed from the last VP8 key frame, they are 0 for other codecs
+	Width, Height uint16
+	// FrameRateNum and FrameRateDen are the frame rate written in the header
+	FrameRateNum, FrameRateDen uint32
+}
+
+// CloseWithResult stops the recording like Close, and returns a summary of it.
+// It is safe to call CloseWithResult multiple times, subsequent calls return the
+// same result and the error of the first one.
+func (i *IVFWriter) CloseWithResult() (CloseResult, error) {
+	if i.ioWriter != nil {
+		i.closeErr = i.close()
+		i.ioWriter = nil
+		i.releaseBudget()
+	}
+
+	return CloseResult{
+		FrameCount:   i.frameCount,
+		Dropped:      i.dropped,
+		Duration:     i.writtenDuration(),
+		BytesWritten: i.offset,
+		Width:        uint16(i.width),
+		Height:       uint16(i.height),
+		FrameRateNum: i.frameRateNum,
+		FrameRateDen: i.frameRateDen,
+	}, i.closeErr
+}
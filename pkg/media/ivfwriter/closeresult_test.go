@@ -0,0 +1,39 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_CloseWithResult(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "out.ivf")
+	writer, err := New(fileName)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(1000, true)))
+	writer.FrameDropped()
+	require.NoError(t, writer.WriteRTP(newVP8Packet(1000+6000, false)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(1000+9000, false)))
+
+	result, err := writer.CloseWithResult()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), result.FrameCount)
+	require.Equal(t, uint64(1), result.Dropped)
+	require.Equal(t, 100*time.Millisecond, result.Duration)
+
+	data, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), result.BytesWritten)
+	require.Equal(t, binary.LittleEndian.Uint32(data[16:]), result.FrameRateNum)
+	require.Equal(t, binary.LittleEndian.Uint32(data[20:]), result.FrameRateDen)
+
+	again, err := writer.CloseWithResult()
+	require.NoError(t, err)
+	require.Equal(t, result, again)
+	require.NoError(t, writer.Close())
+}
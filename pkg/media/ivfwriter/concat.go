@@ -0,0 +1,135 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/livekit/server-sdk-go/pkg/media/framerate"
+)
+
+var errInvalidIVFHeader = errors.New("invalid IVF header")
+
+// Concat writes segments, such as the files written with WithSegments, to out as a single IVF
+// file. The segments must have the same codec and dimensions, otherwise an error wrapping
+// ErrSegmentMismatch is returned. The PTS of the frames of each segment are shifted to follow
+// the ones of the previous segment.
+//
+// The frame rates of the segment headers are estimated by IVFWriter from the frames of each
+// segment, so they are not required to match: the header written has the average frame rate of
+// the segments, weighted by their frame counts. The frame count of the header is the sum of the
+// counts of the segment headers. It is updated with the number of frames written if out is an
+// io.WriteSeeker.
+func Concat(out io.Writer, segments ...io.Reader) error {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	var header []byte
+	var frameCount uint32
+	var duration float64
+	for n, segment := range segments {
+		segmentHeader := make([]byte, ivfFileHeaderSize)
+		if _, err := io.ReadFull(segment, segmentHeader); err != nil {
+			return fmt.Errorf("segment %d: %w", n, err)
+		}
+		if string(segmentHeader[:4]) != ivfFileHeaderSignature || binary.LittleEndian.Uint16(segmentHeader[6:]) != ivfFileHeaderSize {
+			return fmt.Errorf("segment %d: %w", n, errInvalidIVFHeader)
+		}
+
+		if header == nil {
+			header = segmentHeader
+		} else if err := matchSegmentHeaders(header, segmentHeader); err != nil {
+			return fmt.Errorf("segment %d: %w", n, err)
+		}
+		count := binary.LittleEndian.Uint32(segmentHeader[24:])
+		if num, den := binary.LittleEndian.Uint32(segmentHeader[16:]), binary.LittleEndian.Uint32(segmentHeader[20:]); num > 0 && den > 0 {
+			duration += float64(count) * float64(den) / float64(num)
+		}
+		frameCount += count
+	}
+
+	if duration > 0 {
+		num, den := framerate.GetBestMatch(float64(frameCount) / duration)
+		binary.LittleEndian.PutUint32(header[16:], num)
+		binary.LittleEndian.PutUint32(header[20:], den)
+	}
+	binary.LittleEndian.PutUint32(header[24:], frameCount)
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+
+	var written uint32
+	var nextPTS uint64
+	for n, segment := range segments {
+		count, lastPTS, err := copySegmentFrames(out, segment, nextPTS, n == 0)
+		if err != nil {
+			return fmt.Errorf("segment %d: %w", n, err)
+		}
+		written += count
+		if count > 0 {
+			nextPTS = lastPTS + 1
+		}
+	}
+
+	if ws, ok := out.(io.WriteSeeker); ok && written != frameCount {
+		if _, err := ws.Seek(24, io.SeekStart); err != nil {
+			return err
+		}
+		buff := make([]byte, 4)
+		binary.LittleEndian.PutUint32(buff, written)
+		if _, err := ws.Write(buff); err != nil {
+			return err
+		}
+		if _, err := ws.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchSegmentHeaders returns an error wrapping ErrSegmentMismatch if the codec or dimensions of the headers differ
+func matchSegmentHeaders(a, b []byte) error {
+	switch {
+	case string(a[8:12]) != string(b[8:12]):
+		return fmt.Errorf("%w: codec %q, expected %q", ErrSegmentMismatch, b[8:12], a[8:12])
+	case string(a[12:16]) != string(b[12:16]):
+		return fmt.Errorf("%w: dimensions %dx%d, expected %dx%d", ErrSegmentMismatch,
+			binary.LittleEndian.Uint16(b[12:]), binary.LittleEndian.Uint16(b[14:]),
+			binary.LittleEndian.Uint16(a[12:]), binary.LittleEndian.Uint16(a[14:]))
+	}
+	return nil
+}
+
+// copySegmentFrames copies the frames of a segment, with PTS shifted to start at firstPTS, or
+// kept as is for the first segment. It returns the number of frames and the last PTS written.
+func copySegmentFrames(out io.Writer, segment io.Reader, firstPTS uint64, keepPTS bool) (count uint32, lastPTS uint64, err error) {
+	frameHeader := make([]byte, ivfFrameHeaderSize)
+	var offset uint64
+	for {
+		if _, err = io.ReadFull(segment, frameHeader); err == io.EOF {
+			return count, lastPTS, nil
+		} else if err != nil {
+			return
+		}
+
+		pts := binary.LittleEndian.Uint64(frameHeader[4:])
+		if count == 0 && !keepPTS {
+			offset = pts
+		}
+		lastPTS = pts - offset + firstPTS
+		binary.LittleEndian.PutUint64(frameHeader[4:], lastPTS)
+
+		if _, err = out.Write(frameHeader); err != nil {
+			return
+		}
+		size := int64(binary.LittleEndian.Uint32(frameHeader))
+		if _, err = io.CopyN(out, segment, size); err == io.EOF {
+			return count, lastPTS, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return
+		}
+		count++
+	}
+}
@@ -0,0 +1,58 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcat(t *testing.T) {
+	dir := t.TempDir()
+	writeSegment := func(name string, frames int, opts ...Option) *os.File {
+		fileName := filepath.Join(dir, name)
+		writer, err := New(fileName, opts...)
+		require.NoError(t, err)
+		for j := 0; j < frames; j++ {
+			require.NoError(t, writer.WriteRTP(newVP8Packet(uint32(j)*3000, j == 0)))
+		}
+		require.NoError(t, writer.Close())
+
+		f, err := os.Open(fileName)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+		return f
+	}
+
+	out, err := os.Create(filepath.Join(dir, "out.ivf"))
+	require.NoError(t, err)
+	defer out.Close()
+	require.NoError(t, Concat(out,
+		writeSegment("a.ivf", 3),
+		writeSegment("b.ivf", 2, WithStartPTS(100)),
+	))
+
+	_, err = out.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	data, err := io.ReadAll(out)
+	require.NoError(t, err)
+	require.Len(t, data, ivfFileHeaderSize+5*(ivfFrameHeaderSize+3))
+	require.Equal(t, uint32(5), binary.LittleEndian.Uint32(data[24:]))
+	// 3 frames at 50 fps and 2 at 60 fps average 53.6 fps, matched to 50 fps
+	require.Equal(t, uint32(50), binary.LittleEndian.Uint32(data[16:]))
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(data[20:]))
+	for j := 0; j < 5; j++ {
+		frame := data[ivfFileHeaderSize+j*(ivfFrameHeaderSize+3):]
+		require.Equal(t, uint64(j), binary.LittleEndian.Uint64(frame[4:]), "frame %d", j)
+	}
+
+	err = Concat(&bytes.Buffer{}, writeSegment("c.ivf", 3), writeSegment("d.ivf", 0, WithCodec(mimeTypeAV1)))
+	require.ErrorIs(t, err, ErrSegmentMismatch)
+
+	err = Concat(&bytes.Buffer{}, bytes.NewReader([]byte("not an IVF file, definitely not!")))
+	require.ErrorIs(t, err, errInvalidIVFHeader)
+}
@@ -0,0 +1,9 @@
+package ivfwriter
+
+// FirstDecodablePoint returns the byte offset in the output, and the PTS, of the first key
+// frame written, from which players can start decoding. For AV1 this is the first sequence
+// header, for custom codecs the first frame. ok is false until such a frame is written. With
+// WithSegments, the offset is the one in the current segment.
+func (i *IVFWriter) FirstDecodablePoint() (offset int64, pts uint64, ok bool) {
+	return i.keyFrameOffset, i.keyFramePTS, i.keyFrameWritten
+}
@@ -0,0 +1,35 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_FirstDecodablePoint(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithStartPTS(10))
+	require.NoError(t, err)
+
+	_, _, ok := writer.FirstDecodablePoint()
+	require.False(t, ok)
+
+	// frames written before the first key frame with WriteFrame
+	require.NoError(t, writer.WriteFrame([]byte{0x00, 0x01, 0x02}, true, 0))
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0x01}, false, 1))
+	offset, pts, ok := writer.FirstDecodablePoint()
+	require.True(t, ok)
+	require.Equal(t, int64(32), offset)
+	require.Equal(t, uint64(10), pts)
+
+	// the first frame is flagged as a key frame by the caller, but is an inter frame
+	writer, err = NewWith(&bytes.Buffer{})
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0x01}, true, 0))
+	require.NoError(t, writer.WriteFrame([]byte{0x00, 0x01, 0x02}, true, 1))
+	require.NoError(t, writer.WriteFrame([]byte{0x00, 0x01, 0x02}, true, 2))
+	offset, pts, ok = writer.FirstDecodablePoint()
+	require.True(t, ok)
+	require.Equal(t, int64(32+12+2), offset)
+	require.Equal(t, uint64(1), pts)
+}
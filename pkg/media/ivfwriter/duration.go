@@ -0,0 +1,53 @@
+package ivfwriter
+
+import (
+	"time"
+)
+
+// RTPDuration returns the time between two RTP timestamps of a stream with the given clock rate.
+// wraps is the number of times the timestamp wrapped around past zero between firstTS and lastTS,
+// which cannot be told from the timestamps alone once a stream is longer than 2^32 ticks, about
+// 13 hours at 90kHz. It is 0 when clockRate is 0.
+func RTPDuration(firstTS, lastTS uint32, clockRate uint32, wraps int) time.Duration {
+	if clockRate == 0 {
+		return 0
+	}
+	ticks := int64(wraps)<<32 + int64(lastTS) - int64(firstTS)
+	return time.Duration(ticks) * time.Second / time.Duration(clockRate)
+}
+
+// Duration returns the duration of the recording, from the RTP timestamps of the first and the
+// last frame written. It is the one reported by Stats.
+func (i *IVFWriter) Duration() time.Duration {
+	return i.writtenDuration()
+}
+
+// setFirstTimestamp sets the timestamp durations are measured from
+func (i *IVFWriter) setFirstTimestamp(timestamp uint32) {
+	i.firstTimestamp = timestamp
+	i.lastTimestamp = timestamp
+	i.timestampWraps = 0
+}
+
+// setLastTimestamp sets the timestamp of the last frame written, counting wraps around zero
+func (i *IVFWriter) setLastTimestamp(timestamp uint32) {
+	if i.disarmed || i.armPending {
+		// the frame was not written
+		return
+	}
+	i.timestampWraps = i.wrapsAt(timestamp)
+	i.lastTimestamp = timestamp
+}
+
+// wrapsAt returns the number of wraps of timestamp since the first one. Timestamps less than half
+// the range before the last one are considered reordered, rather than a wrap.
+func (i *IVFWriter) wrapsAt(timestamp uint32) int {
+	forward := timestamp-i.lastTimestamp < 1<<31
+	switch {
+	case forward && timestamp < i.lastTimestamp:
+		return i.timestampWraps + 1
+	case !forward && timestamp > i.lastTimestamp:
+		return i.timestampWraps - 1
+	}
+	return i.timestampWraps
+}
@@ -0,0 +1,36 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTPDuration(t *testing.T) {
+	require.Equal(t, time.Second, RTPDuration(1000, 91000, 90000, 0))
+	require.Equal(t, time.Second, RTPDuration(0xffffffff-44999, 45000, 90000, 1))
+	require.Equal(t, time.Duration(1<<32)*time.Second/90000+time.Second, RTPDuration(1000, 91000, 90000, 1))
+	require.Equal(t, time.Duration(0), RTPDuration(1000, 91000, 0, 0))
+}
+
+func TestIVFWriter_DurationWraps(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{})
+	require.NoError(t, err)
+
+	ts := uint32(0xf0000000)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(ts, true)))
+	for n := 0; n < 5; n++ {
+		ts += 1 << 30
+		require.NoError(t, writer.WriteRTP(newVP8Packet(ts, false)))
+	}
+	// reordered frames do not count as wraps
+	require.NoError(t, writer.WriteRTP(newVP8Packet(ts-3000, false)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(ts, false)))
+
+	expected := time.Duration(5<<30) * time.Second / 90000
+	require.Equal(t, expected, writer.Duration())
+	require.Equal(t, expected, writer.Stats().Duration)
+	require.NoError(t, writer.Close())
+}
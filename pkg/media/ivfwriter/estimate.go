@@ -0,0 +1,38 @@
+package ivfwriter
+
+import (
+	"time"
+)
+
+// DefaultEstimateFrameRate is the frame rate EstimateSize assumes to account for the frame headers
+const DefaultEstimateFrameRate = 30
+
+// EstimateSize returns a rough estimate of the size in bytes of an IVF file recording a stream
+// of the given bitrate, in bits per second, for duration. See EstimateSizeAt for the overhead
+// of the container, which EstimateSize computes at DefaultEstimateFrameRate.
+func EstimateSize(bitrate float64, duration time.Duration) int64 {
+	return EstimateSizeAt(bitrate, DefaultEstimateFrameRate, duration)
+}
+
+// EstimateSizeAt is EstimateSize for a stream of frameRate frames per second. The container
+// overhead is the same for all codecs written to IVF: the file header, and a header for each
+// frame, so it only depends on the number of frames.
+func EstimateSizeAt(bitrate, frameRate float64, duration time.Duration) int64 {
+	if bitrate < 0 || frameRate < 0 || duration <= 0 {
+		return ivfFileHeaderSize
+	}
+	perSecond := bitrate/8 + frameRate*ivfFrameHeaderSize
+	return ivfFileHeaderSize + int64(perSecond*duration.Seconds())
+}
+
+// ProjectedSize returns the size the current output will have after remaining more time of
+// recording, at the bitrate and frame rate measured since it started. It returns the size
+// written so far until the duration of the recording can be measured.
+func (i *IVFWriter) ProjectedSize(remaining time.Duration) int64 {
+	duration := i.writtenDuration()
+	if duration <= 0 || remaining <= 0 {
+		return i.offset
+	}
+	perSecond := float64(i.bytes+i.frameCount*ivfFrameHeaderSize) / duration.Seconds()
+	return i.offset + int64(perSecond*remaining.Seconds())
+}
@@ -0,0 +1,31 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateSize(t *testing.T) {
+	require.Equal(t, int64(32+125000+30*12), EstimateSize(1e6, time.Second))
+	require.Equal(t, int64(32+2*(125000+60*12)), EstimateSizeAt(1e6, 60, 2*time.Second))
+	require.Equal(t, int64(32), EstimateSize(1e6, 0))
+}
+
+func TestIVFWriter_ProjectedSize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWith(buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(32), writer.ProjectedSize(time.Second))
+
+	for n := 0; n < 4; n++ {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(uint32(n)*3000, n == 0)))
+	}
+	require.Equal(t, int64(buf.Len()), writer.ProjectedSize(0))
+
+	// 4 frames of 3 bytes with their headers in 100ms
+	require.Equal(t, int64(buf.Len()+600), writer.ProjectedSize(time.Second))
+	require.NoError(t, writer.Close())
+}
@@ -0,0 +1,43 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// extensionMagic marks the unused header field of files written with extensions
+const extensionMagic = "LK"
+
+var errInvalidExtensionVersion = errors.New("extension version must be within [1, 65535]")
+
+// WithExtensionVersion tags bytes 28-31 of the header, which are unused by the IVF format,
+// with the "LK" magic followed by version as a little endian uint16. It lets readers detect
+// files using extensions of this writer. Players ignore this field.
+func WithExtensionVersion(version uint32) Option {
+	return func(i *IVFWriter) error {
+		if version == 0 || version > math.MaxUint16 {
+			return errInvalidExtensionVersion
+		}
+		i.extensionVersion = uint16(version)
+		return nil
+	}
+}
+
+// writeExtensionTag tags the unused field of the header, which is left as is without extensions
+func (i *IVFWriter) writeExtensionTag(field []byte) {
+	if i.extensionVersion == 0 {
+		return
+	}
+	copy(field, extensionMagic)
+	binary.LittleEndian.PutUint16(field[2:], i.extensionVersion)
+}
+
+// ExtensionVersion returns the extension version tagged in an IVF file header,
+// and false if the file was written without extensions.
+func ExtensionVersion(header []byte) (uint32, bool) {
+	if len(header) < 32 || string(header[28:30]) != extensionMagic {
+		return 0, false
+	}
+	return uint32(binary.LittleEndian.Uint16(header[30:])), true
+}
@@ -0,0 +1,27 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_ExtensionVersion(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithExtensionVersion(1<<16))
+	require.ErrorIs(t, err, errInvalidExtensionVersion)
+
+	buf := &bytes.Buffer{}
+	_, err = NewWith(buf)
+	require.NoError(t, err)
+	_, ok := ExtensionVersion(buf.Bytes())
+	require.False(t, ok)
+
+	buf = &bytes.Buffer{}
+	_, err = NewWith(buf, WithExtensionVersion(2))
+	require.NoError(t, err)
+	require.Equal(t, []byte{'L', 'K', 2, 0}, buf.Bytes()[28:32])
+	version, ok := ExtensionVersion(buf.Bytes())
+	require.True(t, ok)
+	require.Equal(t, uint32(2), version)
+}
@@ -0,0 +1,92 @@
+package ivfwriter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+)
+
+var errUnknownFrameStatsFormat = errors.New("unknown frame stats format")
+
+// FrameStatsFormat is the encoding of the frame stats written by WriteFrameStats
+type FrameStatsFormat int
+
+const (
+	// FrameStatsCSV writes a header row with the JSON names of the FrameStat fields, then a row per frame
+	FrameStatsCSV FrameStatsFormat = iota
+	// FrameStatsJSON writes a JSON array of FrameStat
+	FrameStatsJSON
+)
+
+// FrameStat describes a written frame. KeyFrame is only detected for VP8 frames and AV1 sequence
+// headers. RTPTimestamp and FragmentCount are 0 for frames written with WriteFrame.
+type FrameStat struct {
+	Index         int    `json:"index"`
+	KeyFrame      bool   `json:"keyframe"`
+	Size          int    `json:"size"`
+	RTPTimestamp  uint32 `json:"rtp_timestamp"`
+	PTS           uint64 `json:"pts"`
+	FragmentCount int    `json:"fragment_count"`
+}
+
+// WithFrameStats keeps a FrameStat for every written frame, to export them with WriteFrameStats
+func WithFrameStats() Option {
+	return func(i *IVFWriter) error {
+		i.collectFrameStats = true
+		return nil
+	}
+}
+
+func (i *IVFWriter) recordFrameStat(frame []byte, pts uint64) {
+	i.frameStats = append(i.frameStats, FrameStat{
+		Index:         len(i.frameStats),
+		KeyFrame:      i.isKeyFrame(frame),
+		Size:          len(frame),
+		RTPTimestamp:  i.frameTimestamp,
+		PTS:           pts,
+		FragmentCount: i.frameFragments,
+	})
+}
+
+// FrameStats returns the stats of the frames written so far, when enabled with WithFrameStats
+func (i *IVFWriter) FrameStats() []FrameStat {
+	return i.frameStats
+}
+
+// WriteFrameStats exports the stats of the written frames as CSV, with a header row, or as a JSON array.
+// It is typically called after Close.
+func (i *IVFWriter) WriteFrameStats(w io.Writer, format FrameStatsFormat) error {
+	switch format {
+	case FrameStatsCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"index", "keyframe", "size", "rtp_timestamp", "pts", "fragment_count"}); err != nil {
+			return err
+		}
+		for _, s := range i.frameStats {
+			if err := cw.Write([]string{
+				strconv.Itoa(s.Index),
+				strconv.FormatBool(s.KeyFrame),
+				strconv.Itoa(s.Size),
+				strconv.FormatUint(uint64(s.RTPTimestamp), 10),
+				strconv.FormatUint(s.PTS, 10),
+				strconv.Itoa(s.FragmentCount),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FrameStatsJSON:
+		stats := i.frameStats
+		if stats == nil {
+			stats = []FrameStat{}
+		}
+		return json.NewEncoder(w).Encode(stats)
+
+	default:
+		return errUnknownFrameStatsFormat
+	}
+}
@@ -0,0 +1,44 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_FrameStats(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithFrameStats())
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	first := newVP8Packet(3000, false)
+	first.Marker = false
+	require.NoError(t, writer.WriteRTP(first))
+	require.NoError(t, writer.WriteRTP(&rtp.Packet{
+		Header:  rtp.Header{Timestamp: 3000, Marker: true},
+		Payload: []byte{0x00, 0xff, 0xff, 0xff},
+	}))
+	require.NoError(t, writer.Close())
+
+	require.Equal(t, []FrameStat{
+		{Index: 0, KeyFrame: true, Size: 3, RTPTimestamp: 0, PTS: 0, FragmentCount: 1},
+		{Index: 1, KeyFrame: false, Size: 6, RTPTimestamp: 3000, PTS: 1, FragmentCount: 2},
+	}, writer.FrameStats())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writer.WriteFrameStats(buf, FrameStatsCSV))
+	require.Equal(t, "index,keyframe,size,rtp_timestamp,pts,fragment_count\n"+
+		"0,true,3,0,0,1\n"+
+		"1,false,6,3000,1,2\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writer.WriteFrameStats(buf, FrameStatsJSON))
+	require.JSONEq(t, `[
+		{"index":0,"keyframe":true,"size":3,"rtp_timestamp":0,"pts":0,"fragment_count":1},
+		{"index":1,"keyframe":false,"size":6,"rtp_timestamp":3000,"pts":1,"fragment_count":2}
+	]`, buf.String())
+
+	require.ErrorIs(t, writer.WriteFrameStats(buf, FrameStatsFormat(5)), errUnknownFrameStatsFormat)
+}
@@ -0,0 +1,87 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// readCapture reads an RTP capture from testdata. Captures are a sequence of
+// packets, each prefixed with its length as a big endian uint16.
+func readCapture(t *testing.T, name string) []*rtp.Packet {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	var packets []*rtp.Packet
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 2, "truncated capture")
+		size := int(binary.BigEndian.Uint16(data))
+		require.GreaterOrEqual(t, len(data), 2+size, "truncated capture")
+
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(data[2:2+size]))
+		packets = append(packets, packet)
+		data = data[2+size:]
+	}
+	return packets
+}
+
+func TestIVFWriter_Golden(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		capture string
+		opts    []Option
+	}{
+		{
+			name:    "vp8",
+			capture: "vp8.rtp",
+		},
+		{
+			name:    "vp8_loss",
+			capture: "vp8_loss.rtp",
+			opts:    []Option{WithLossThreshold(0.1, 10)},
+		},
+		{
+			name:    "av1",
+			capture: "av1.rtp",
+			opts:    []Option{WithCodec(mimeTypeAV1)},
+		},
+		{
+			name:    "av1_loss",
+			capture: "av1_loss.rtp",
+			opts:    []Option{WithCodec(mimeTypeAV1), WithLossThreshold(0.1, 10)},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), tc.name+".ivf")
+			writer, err := New(fileName, tc.opts...)
+			require.NoError(t, err)
+			for _, packet := range readCapture(t, tc.capture) {
+				require.NoError(t, writer.WriteRTP(packet))
+			}
+			require.NoError(t, writer.Close())
+
+			got, err := ioutil.ReadFile(fileName)
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", tc.name+".ivf")
+			if *update {
+				require.NoError(t, ioutil.WriteFile(golden, got, 0644))
+			}
+			want, err := ioutil.ReadFile(golden)
+			if os.IsNotExist(err) {
+				t.Fatalf("missing golden file %s, run with -update to create it", golden)
+			}
+			require.NoError(t, err)
+			require.Equal(t, want, got, "output differs from %s, run with -update if the change is intended", golden)
+		})
+	}
+}
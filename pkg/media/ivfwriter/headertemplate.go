@@ -0,0 +1,64 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+)
+
+// IVFHeader holds the fields of the 32 bytes IVF file header, which starts with the "DKIF"
+// signature followed by the fields, little endian, at the offsets given below. The header size
+// field, at offset 6, is always 32.
+type IVFHeader struct {
+	// Version, at offset 4, is 0
+	Version uint16
+	// FOURCC, at offset 8, identifies the codec, such as "VP80" or "AV01"
+	FOURCC string
+	// Width and Height in pixels, at offsets 12 and 14
+	Width, Height uint16
+	// FrameRateNum and FrameRateDen, at offsets 16 and 20, are the frame rate, which is also the
+	// time base of the frame PTS
+	FrameRateNum, FrameRateDen uint32
+	// FrameCount, at offset 24, is the number of frames of the file
+	FrameCount uint32
+	// Unused, at offset 28, is ignored by players, see WithExtensionVersion
+	Unused uint32
+}
+
+// defaultHeader is the header written without WithHeaderTemplate, the frame rate and count are
+// estimates updated on Close
+var defaultHeader = IVFHeader{
+	Width:        640,
+	Height:       480,
+	FrameRateNum: 24,
+	FrameRateDen: 1,
+	FrameCount:   900,
+}
+
+// WithHeaderTemplate writes header as the IVF header, instead of the default one. An empty FOURCC
+// is replaced by the one of the codec. Like for the default header, the frame rate and count are
+// updated on Close when the output is an io.WriteSeeker, and the unused field is replaced when
+// WithExtensionVersion is set.
+func WithHeaderTemplate(header IVFHeader) Option {
+	return func(i *IVFWriter) error {
+		if header.FOURCC != "" && len(header.FOURCC) != 4 {
+			return errInvalidFOURCC
+		}
+		i.headerTemplate = &header
+		return nil
+	}
+}
+
+// marshal encodes the header
+func (h *IVFHeader) marshal() []byte {
+	header := make([]byte, ivfFileHeaderSize)
+	copy(header[0:], ivfFileHeaderSignature)
+	binary.LittleEndian.PutUint16(header[4:], h.Version)
+	binary.LittleEndian.PutUint16(header[6:], ivfFileHeaderSize)
+	copy(header[8:12], h.FOURCC)
+	binary.LittleEndian.PutUint16(header[12:], h.Width)
+	binary.LittleEndian.PutUint16(header[14:], h.Height)
+	binary.LittleEndian.PutUint32(header[16:], h.FrameRateNum)
+	binary.LittleEndian.PutUint32(header[20:], h.FrameRateDen)
+	binary.LittleEndian.PutUint32(header[24:], h.FrameCount)
+	binary.LittleEndian.PutUint32(header[28:], h.Unused)
+	return header
+}
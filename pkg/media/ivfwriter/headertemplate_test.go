@@ -0,0 +1,62 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_HeaderTemplate(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithHeaderTemplate(IVFHeader{FOURCC: "VP8"}))
+	require.ErrorIs(t, err, errInvalidFOURCC)
+
+	template := IVFHeader{
+		Version:      1,
+		Width:        1920,
+		Height:       1080,
+		FrameRateNum: 30000,
+		FrameRateDen: 1001,
+		FrameCount:   1,
+		Unused:       0xdeadbeef,
+	}
+	buf := &bytes.Buffer{}
+	_, err = NewWith(buf, WithHeaderTemplate(template))
+	require.NoError(t, err)
+
+	header := buf.Bytes()
+	require.Equal(t, "DKIF", string(header[:4]))
+	require.Equal(t, uint16(1), binary.LittleEndian.Uint16(header[4:]))
+	require.Equal(t, uint16(32), binary.LittleEndian.Uint16(header[6:]))
+	require.Equal(t, "VP80", string(header[8:12]), "FOURCC of the codec")
+	require.Equal(t, uint16(1920), binary.LittleEndian.Uint16(header[12:]))
+	require.Equal(t, uint16(1080), binary.LittleEndian.Uint16(header[14:]))
+	require.Equal(t, uint32(30000), binary.LittleEndian.Uint32(header[16:]))
+	require.Equal(t, uint32(1001), binary.LittleEndian.Uint32(header[20:]))
+	require.Equal(t, uint32(1), binary.LittleEndian.Uint32(header[24:]))
+	require.Equal(t, uint32(0xdeadbeef), binary.LittleEndian.Uint32(header[28:]))
+
+	template.FOURCC = "XVP8"
+	buf = &bytes.Buffer{}
+	_, err = NewWith(buf, WithHeaderTemplate(template), WithExtensionVersion(1))
+	require.NoError(t, err)
+	require.Equal(t, "XVP8", string(buf.Bytes()[8:12]))
+	require.Equal(t, []byte{'L', 'K', 1, 0}, buf.Bytes()[28:32])
+}
+
+func TestIVFWriter_HeaderTemplateUpdated(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "out.ivf")
+	writer, err := New(fileName, WithHeaderTemplate(IVFHeader{Width: 320, Height: 240, FrameRateNum: 1, FrameRateDen: 1}))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	require.NoError(t, writer.Close())
+
+	header, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+	require.Equal(t, uint16(320), binary.LittleEndian.Uint16(header[12:]))
+	require.Equal(t, uint32(2), binary.LittleEndian.Uint32(header[24:]), "frame count updated on Close")
+}
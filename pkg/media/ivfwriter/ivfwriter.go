@@ -4,8 +4,11 @@ package ivfwriter
 import (
 	"encoding/binary"
 	"errors"
+	"hash"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
@@ -19,34 +22,181 @@ var (
 	errInvalidNilPacket = errors.New("invalid nil packet")
 	errCodecAlreadySet  = errors.New("codec is already set")
 	errNoSuchCodec      = errors.New("no codec for this MimeType")
+	errInvalidFOURCC    = errors.New("FOURCC must be 4 characters")
+	errInvalidClockRate = errors.New("clock rate must be positive")
+	errNotFrameBoundary = errors.New("a frame is being reassembled")
+
+	// ErrMaxDurationReached is returned by WriteRTP once the duration set by WithMaxDuration is exceeded
+	ErrMaxDurationReached = errors.New("max duration reached")
+
+	// ErrMaxFramesReached is returned by WriteRTP once the number of frames set by WithMaxFrames was written
+	ErrMaxFramesReached = errors.New("max frames reached")
+
+	// ErrNoKeyframe is returned by WriteRTP while no key frame was received within the timeout set by WithKeyframeTimeout
+	ErrNoKeyframe = errors.New("no key frame received")
+
+	// ErrSegmentMismatch is returned by Concat when segments differ in codec or dimensions
+	ErrSegmentMismatch = errors.New("segments do not match")
 )
 
 const (
 	mimeTypeVP8         = "video/VP8"
 	defaultVP8ClockRate = 90000
 	mimeTypeAV1         = "video/AV1"
+	defaultAV1ClockRate = 90000
 
 	ivfFileHeaderSignature = "DKIF"
+
+	obuTypeSequenceHeader = 1
 )
 
 // IVFWriter is used to take RTP packets and write them to an IVF on disk
 type IVFWriter struct {
 	ioWriter     io.Writer
+	logger       Logger
 	seenKeyFrame bool
 
 	isVP8, isAV1 bool
 
-	// VP8
+	// VP8 and custom codecs
 	currentFrame []byte
 
 	// AV1
 	av1Frame frame.AV1
+	// bytes of the OBU fragment buffered by av1Frame, when bounded by WithMaxAV1BufferSize
+	av1Buffered      int
+	maxAV1BufferSize int
+	av1Resyncing     bool
+
+	// AV1 SVC
+	filterSpatialLayer bool
+	maxSpatialLayer    int
+
+	// VP8 temporal scalability, see WithMaxTemporalLayer
+	filterTemporalLayer   bool
+	maxTemporalLayer      int
+	temporalLayerSynced   [maxTemporalLayers]bool
+	tl0PicIdx             uint8
+	tl0PicIdxWritten      bool
+	droppingTemporalFrame bool
+	droppedFrameTimestamp uint32
+
+	// custom codec
+	fourcc       string
+	depacketizer rtp.Depacketizer
+
+	headerTemplate *IVFHeader
+
+	// path the output file is renamed to on Close, see WithAtomicFinalize
+	finalPath string
+
+	// set on the first written packet, for codecs that are not gated on key frames
+	started bool
 
 	frameCount uint64
+	dropped    uint64
+	bytes      uint64
+
+	// offset added to the PTS of the frames written
+	startPTS uint64
+
+	// size of the output written so far, and position of the first key frame
+	offset          int64
+	keyFrameOffset  int64
+	keyFramePTS     uint64
+	keyFrameWritten bool
+
+	// dimensions of the last VP8 key frame
+	width, height uint32
 
 	clockRate      uint32
 	firstTimestamp uint32
 	lastTimestamp  uint32
+	timestampWraps int
+
+	// duration written before the last clock rate change
+	clockRateDuration time.Duration
+	clockRateChanged  bool
+
+	// frame rate written in the header
+	frameRateNum, frameRateDen uint32
+
+	maxDuration        time.Duration
+	maxDurationReached bool
+
+	maxFrames        uint64
+	framesWritten    uint64
+	maxFramesReached bool
+
+	onCodecDetected   func(mimeType string)
+	codecDetectionEnd bool
+
+	lossMonitor        *lossMonitor
+	lossPaused         bool
+	lossPausedAt       uint32
+	lossPausedUntil    uint32
+	lossPausedDuration time.Duration
+
+	chapters       []chapter
+	chapterSidecar io.Writer
+
+	preRoll *preRoll
+
+	extensionVersion uint16
+
+	budget           Budget
+	maxBufferedBytes int
+
+	openSegment SegmentOpener
+	segment     int
+	cutPending  bool
+
+	maxOpenDuration time.Duration
+	segmentOpenedAt time.Time
+
+	observer          Observer
+	fragmentCount     int
+	fragmentTimestamp uint32
+
+	// RTP timestamp and fragments of the frame being written, 0 when not written from RTP
+	frameTimestamp    uint32
+	frameFragments    int
+	collectFrameStats bool
+	frameStats        []FrameStat
+
+	overflowPolicy     OverflowPolicy
+	queueSize          int
+	async              *asyncWriter
+	droppingToKeyframe bool
+
+	transformFrame FrameTransformer
+
+	onAnomaly func(Anomaly)
+
+	checksum    hash.Hash
+	checksumSum []byte
+
+	// frames written since the last sync, see WithSyncEvery
+	syncEvery, unsynced int
+
+	// set by Disarm, and by Arm until the next key frame
+	disarmed, armPending bool
+
+	progressInterval time.Duration
+	nextProgress     time.Duration
+	onProgress       func(IVFStats)
+
+	keyFrameTimeout time.Duration
+	firstPacketAt   time.Time
+
+	syntheticFrame    []byte
+	syntheticTimeout  time.Duration
+	syntheticDeadline time.Time
+
+	// clock used for timeouts, time.Now unless overridden by tests
+	now func() time.Time
+
+	closeErr error
 }
 
 // New builds a new IVF writer
@@ -57,6 +207,8 @@ func New(fileName string, opts ...Option) (*IVFWriter, error) {
 	}
 	writer, err := NewWith(f, opts...)
 	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(fileName)
 		return nil, err
 	}
 	writer.ioWriter = f
@@ -71,7 +223,9 @@ func NewWith(out io.Writer, opts ...Option) (*IVFWriter, error) {
 
 	writer := &IVFWriter{
 		ioWriter:     out,
+		logger:       nopLogger{},
 		seenKeyFrame: false,
+		now:          time.Now,
 	}
 
 	for _, o := range opts {
@@ -80,49 +234,136 @@ func NewWith(out io.Writer, opts ...Option) (*IVFWriter, error) {
 		}
 	}
 
-	if !writer.isAV1 && !writer.isVP8 {
+	if !writer.isAV1 && !writer.isVP8 && writer.depacketizer == nil {
 		writer.isVP8 = true
 		if writer.clockRate == 0 {
 			writer.clockRate = defaultVP8ClockRate
 		}
 	}
 
+	if writer.maxOpenDuration > 0 {
+		if writer.openSegment == nil {
+			return nil, errNoSegmentOpener
+		}
+		writer.segmentOpenedAt = writer.now()
+	}
+
+	if err := writer.checkAtomicFinalize(); err != nil {
+		return nil, err
+	}
+
+	if writer.preRoll != nil && !writer.isVP8 {
+		return nil, errPreRollCodec
+	}
+
+	if writer.syntheticFrame != nil {
+		if !writer.isVP8 {
+			return nil, errSyntheticFrameCodec
+		}
+		writer.syntheticDeadline = writer.now().Add(writer.syntheticTimeout)
+	}
+
+	if writer.budget != nil {
+		if err := writer.budget.Acquire(writer.maxBufferedBytes); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := writer.writeHeader(); err != nil {
+		if writer.budget != nil {
+			writer.budget.Release(writer.maxBufferedBytes)
+		}
 		return nil, err
 	}
+	if writer.queueSize > 0 {
+		writer.async = newAsyncWriter(writer.ioWriter, writer.queueSize)
+	}
 	return writer, nil
 }
 
 func (i *IVFWriter) writeHeader() error {
-	header := make([]byte, 32)
-	copy(header[0:], ivfFileHeaderSignature)      // DKIF
-	binary.LittleEndian.PutUint16(header[4:], 0)  // Version
-	binary.LittleEndian.PutUint16(header[6:], 32) // Header size
-
-	// FOURCC
-	if i.isVP8 {
-		copy(header[8:], "VP80")
-	} else if i.isAV1 {
-		copy(header[8:], "AV01")
+	h := defaultHeader
+	if i.headerTemplate != nil {
+		h = *i.headerTemplate
 	}
+	if h.FOURCC == "" {
+		switch {
+		case i.isVP8:
+			h.FOURCC = "VP80"
+		case i.isAV1:
+			h.FOURCC = "AV01"
+		default:
+			h.FOURCC = i.fourcc
+		}
+	}
+	i.frameRateNum, i.frameRateDen = h.FrameRateNum, h.FrameRateDen
 
-	binary.LittleEndian.PutUint16(header[12:], 640) // Width in pixels
-	binary.LittleEndian.PutUint16(header[14:], 480) // Height in pixels
-	binary.LittleEndian.PutUint32(header[16:], 24)  // Framerate numerator (updated on Close)
-	binary.LittleEndian.PutUint32(header[20:], 1)   // Framerate denominator (updated on Close)
-	binary.LittleEndian.PutUint32(header[24:], 900) // Frame count (updated on Close)
-	binary.LittleEndian.PutUint32(header[28:], 0)   // Unused
+	header := h.marshal()
+	i.writeExtensionTag(header[28:])
 
+	i.offset = int64(len(header))
+	i.hashOutput(header)
 	_, err := i.ioWriter.Write(header)
 	return err
 }
 
 func (i *IVFWriter) writeFrame(frame []byte) error {
-	frameHeader := make([]byte, 12)
+	return i.writeFrameWithPTS(frame, i.frameCount)
+}
+
+func (i *IVFWriter) writeFrameWithPTS(frame []byte, pts uint64) error {
+	if i.disarmed || i.maxFramesReached || i.armPending && !i.resumeArmed(frame) {
+		return nil
+	}
+	if i.async != nil && i.dropOnOverflow(frame) {
+		if pts >= i.frameCount {
+			i.frameCount = pts + 1
+		}
+		i.dropped++
+		return nil
+	}
+	if i.transformFrame != nil {
+		var err error
+		if frame, err = i.transformFrame(frame, i.isKeyFrame(frame)); err != nil || len(frame) == 0 {
+			return err
+		}
+	}
+
+	frameHeader := make([]byte, 12, 12+len(frame))
 	binary.LittleEndian.PutUint32(frameHeader[0:], uint32(len(frame))) // Frame length
-	binary.LittleEndian.PutUint64(frameHeader[4:], i.frameCount)       // PTS
-	i.frameCount++
+	binary.LittleEndian.PutUint64(frameHeader[4:], i.startPTS+pts)     // PTS
+	if pts >= i.frameCount {
+		i.frameCount = pts + 1
+	}
+	i.bytes += uint64(len(frame))
+	if !i.keyFrameWritten && (i.isKeyFrame(frame) || !i.isVP8 && !i.isAV1) {
+		i.keyFrameWritten = true
+		i.keyFrameOffset = i.offset
+		i.keyFramePTS = i.startPTS + pts
+	}
+	i.offset += int64(len(frameHeader) + len(frame))
+	if i.isVP8 {
+		i.parseVP8Dimensions(frame)
+	}
+	if i.collectFrameStats {
+		i.recordFrameStat(frame, i.startPTS+pts)
+	}
+
+	if err := i.writeFrameData(frameHeader, frame); err != nil {
+		return err
+	}
+	i.countWrittenFrame()
+	if i.syncEvery > 0 {
+		return i.syncPeriodically()
+	}
+	return nil
+}
 
+func (i *IVFWriter) writeFrameData(frameHeader, frame []byte) error {
+	i.hashOutput(frameHeader, frame)
+	if i.async != nil {
+		return i.async.write(append(frameHeader, frame...))
+	}
 	if _, err := i.ioWriter.Write(frameHeader); err != nil {
 		return err
 	}
@@ -130,44 +371,173 @@ func (i *IVFWriter) writeFrame(frame []byte) error {
 	return err
 }
 
+// WriteFrame writes a complete frame, bypassing RTP reassembly. Frames are dropped
+// until the first key frame. pts is expressed in frames, like the PTS WriteRTP writes,
+// and is offset by WithStartPTS.
+func (i *IVFWriter) WriteFrame(data []byte, keyframe bool, pts uint64) error {
+	if i.ioWriter == nil {
+		return errFileNotOpened
+	} else if i.maxDurationReached {
+		return ErrMaxDurationReached
+	} else if i.maxFramesReached {
+		return ErrMaxFramesReached
+	} else if len(data) == 0 {
+		return nil
+	}
+
+	if !i.seenKeyFrame {
+		if !keyframe {
+			i.logger.Debugf("dropping frame before the first key frame")
+			return nil
+		}
+		i.seenKeyFrame = true
+		i.logger.Debugf("first key frame received")
+	}
+
+	i.frameTimestamp, i.frameFragments = 0, 0
+	return i.writeFrameWithPTS(data, pts)
+}
+
+// WriteFrameDetect is like WriteFrame, for callers which do not know if the frame is a key frame.
+// Key frames are detected from the frame itself, like for frames reassembled by WriteRTP. Frames of
+// custom codecs are all considered key frames, since their key frames cannot be detected.
+func (i *IVFWriter) WriteFrameDetect(data []byte, pts uint64) error {
+	return i.WriteFrame(data, i.isKeyFrame(data) || !i.isVP8 && !i.isAV1, pts)
+}
+
 // WriteRTP adds a new packet and writes the appropriate headers for it
 func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
+	if err := i.writeRTP(packet); err != nil {
+		return err
+	}
+	if i.onProgress != nil {
+		i.reportProgress()
+	}
+	return nil
+}
+
+func (i *IVFWriter) writeRTP(packet *rtp.Packet) error {
 	if i.ioWriter == nil {
 		return errFileNotOpened
+	} else if i.maxDurationReached {
+		return ErrMaxDurationReached
+	} else if i.maxFramesReached {
+		return ErrMaxFramesReached
 	} else if len(packet.Payload) == 0 {
+		i.skip(AnomalyEmptyPayload)
 		return nil
 	}
 
+	if i.firstPacketAt.IsZero() {
+		i.firstPacketAt = i.now()
+	}
+	if i.maxOpenDuration > 0 && !i.cutPending && i.now().Sub(i.segmentOpenedAt) >= i.maxOpenDuration {
+		i.logger.Debugf("segment %d open for %s, cutting", i.segment, i.maxOpenDuration)
+		i.cutPending = true
+	}
+
+	if i.clockRateChanged {
+		// timestamps are measured from the first frame written with the new clock rate
+		i.clockRateChanged = false
+		i.setFirstTimestamp(packet.Timestamp)
+	}
+
+	if i.onCodecDetected != nil && !i.codecDetectionEnd {
+		i.detectCodec(packet.Payload)
+	}
+
+	if i.lossMonitor != nil && i.lossMonitor.push(packet.SequenceNumber) && !i.lossPaused && (i.seenKeyFrame || i.isAV1 && i.started) {
+		i.pauseOnLoss(packet.Timestamp)
+	}
+
 	if i.isVP8 {
 		vp8Packet := codecs.VP8Packet{}
 		if _, err := vp8Packet.Unmarshal(packet.Payload); err != nil {
 			return err
 		}
+		if i.droppingTemporalFrame {
+			if packet.Timestamp == i.droppedFrameTimestamp {
+				return nil
+			}
+			i.droppingTemporalFrame = false
+		}
 
 		isKeyFrame := vp8Packet.Payload[0] & 0x01
+		if i.lossPaused {
+			if vp8Packet.S != 1 || isKeyFrame == 1 {
+				i.lossPausedUntil = packet.Timestamp
+				i.skip(AnomalyLossPaused)
+				return nil
+			}
+			i.resumeAfterLoss(packet.Timestamp)
+		}
+
+		if i.preRoll != nil && i.preRoll.triggered && !i.preRoll.flushed {
+			if err := i.flushPreRoll(packet.Timestamp); err != nil {
+				return err
+			}
+		}
+
+		if vp8Packet.S == 1 && isKeyFrame == 1 && i.currentFrame == nil {
+			if err := i.writeSyntheticFrame(packet.Timestamp); err != nil {
+				return err
+			}
+		}
+
 		switch {
 		case !i.seenKeyFrame && isKeyFrame == 1:
+			if err := i.keyFrameWaitErr(); err != nil {
+				return err
+			}
+			i.skip(AnomalyBeforeKeyFrame)
 			return nil
 		case i.currentFrame == nil && vp8Packet.S != 1:
+			i.skip(AnomalyMidFrameStart)
 			return nil
 		case !i.seenKeyFrame:
 			i.seenKeyFrame = true
-			i.firstTimestamp = packet.Timestamp
+			i.setFirstTimestamp(packet.Timestamp)
+			i.logger.Debugf("first key frame received, timestamp %d", packet.Timestamp)
+		case i.cutPending && i.currentFrame == nil && isKeyFrame == 0:
+			if err := i.cut(packet.Timestamp); err != nil {
+				return err
+			}
+		}
+
+		if i.currentFrame == nil && !i.preRolling() && i.exceedsMaxDuration(packet.Timestamp) {
+			return ErrMaxDurationReached
 		}
 
+		if i.filterTemporalLayer && i.currentFrame == nil && !i.keepTemporalLayer(&vp8Packet, isKeyFrame == 0) {
+			i.droppingTemporalFrame = true
+			i.droppedFrameTimestamp = packet.Timestamp
+			return nil
+		}
+
+		i.addFragment(packet.Timestamp, i.currentFrame == nil)
 		i.currentFrame = append(i.currentFrame, vp8Packet.Payload[0:]...)
+		if i.exceedsBufferedBytes() {
+			return ErrFrameTooLarge
+		}
 
 		if !packet.Marker {
 			return nil
 		} else if len(i.currentFrame) == 0 {
 			return nil
 		}
+		i.frameReassembled(len(i.currentFrame), packet.Timestamp)
+
+		if i.preRolling() {
+			i.preRoll.push(i.currentFrame, packet.Timestamp, i.frameFragments)
+			i.currentFrame = nil
+			return nil
+		}
 
 		if err := i.writeFrame(i.currentFrame); err != nil {
 			return err
 		}
 
-		i.lastTimestamp = packet.Timestamp
+		i.setLastTimestamp(packet.Timestamp)
 		i.currentFrame = nil
 	} else if i.isAV1 {
 		av1Packet := &codecs.AV1Packet{}
@@ -175,73 +545,327 @@ func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
 			return err
 		}
 
+		if i.lossPaused {
+			if !av1Packet.N {
+				i.lossPausedUntil = packet.Timestamp
+				i.skip(AnomalyLossPaused)
+				return nil
+			}
+			i.resumeAfterLoss(packet.Timestamp)
+		}
+		if i.av1Resyncing {
+			if !av1Packet.N {
+				i.skip(AnomalyAV1Resync)
+				return nil
+			}
+			i.av1Resyncing = false
+		}
+
+		if !i.started {
+			i.started = true
+			i.setFirstTimestamp(packet.Timestamp)
+		} else if i.cutPending && av1Packet.N {
+			if err := i.cut(packet.Timestamp); err != nil {
+				return err
+			}
+		} else if i.exceedsMaxDuration(packet.Timestamp) {
+			return ErrMaxDurationReached
+		}
+
+		if i.exceedsAV1BufferSize(av1Packet) {
+			i.skip(AnomalyAV1Resync)
+			return nil
+		}
+		i.addFragment(packet.Timestamp, i.fragmentCount == 0)
 		obus, err := i.av1Frame.ReadFrames(av1Packet)
 		if err != nil {
 			return err
 		}
+		if len(obus) > 0 {
+			size := 0
+			for _, obu := range obus {
+				size += len(obu)
+			}
+			i.frameReassembled(size, packet.Timestamp)
+		}
 
 		for j := range obus {
+			if i.filterSpatialLayer && obuSpatialLayer(obus[j]) > i.maxSpatialLayer {
+				continue
+			}
 			if err := i.writeFrame(obus[j]); err != nil {
 				return err
 			}
 		}
+		if len(obus) > 0 {
+			i.setLastTimestamp(packet.Timestamp)
+		}
+	} else if i.depacketizer != nil {
+		payload, err := i.depacketizer.Unmarshal(packet.Payload)
+		if err != nil {
+			return err
+		}
+
+		if i.currentFrame == nil {
+			if !i.depacketizer.IsPartitionHead(packet.Payload) {
+				i.skip(AnomalyMidFrameStart)
+				return nil
+			}
+			if !i.started {
+				i.started = true
+				i.setFirstTimestamp(packet.Timestamp)
+			} else if i.cutPending {
+				if err := i.cut(packet.Timestamp); err != nil {
+					return err
+				}
+			} else if i.exceedsMaxDuration(packet.Timestamp) {
+				return ErrMaxDurationReached
+			}
+		}
+
+		i.addFragment(packet.Timestamp, i.currentFrame == nil)
+		i.currentFrame = append(i.currentFrame, payload...)
+		if i.exceedsBufferedBytes() {
+			return ErrFrameTooLarge
+		}
+		if !i.depacketizer.IsPartitionTail(packet.Marker, packet.Payload) {
+			return nil
+		}
+		i.frameReassembled(len(i.currentFrame), packet.Timestamp)
+
+		if err := i.writeFrame(i.currentFrame); err != nil {
+			return err
+		}
+
+		i.setLastTimestamp(packet.Timestamp)
+		i.currentFrame = nil
 	}
 
 	return nil
 }
 
+// detectCodec looks for a VP8 key frame start code or an AV1 sequence header
+// at the beginning of the payload, and reports the codec if it is not the one
+// the writer was configured with. Detection stops at the first conclusive packet.
+func (i *IVFWriter) detectCodec(payload []byte) {
+	var mimeType string
+	vp8Packet := codecs.VP8Packet{}
+	av1Packet := codecs.AV1Packet{}
+	if _, err := vp8Packet.Unmarshal(payload); err == nil && vp8Packet.S == 1 && vp8Packet.PID == 0 &&
+		len(vp8Packet.Payload) >= 6 && vp8Packet.Payload[0]&0x01 == 0 &&
+		vp8Packet.Payload[3] == 0x9d && vp8Packet.Payload[4] == 0x01 && vp8Packet.Payload[5] == 0x2a {
+		mimeType = mimeTypeVP8
+	} else if _, err := av1Packet.Unmarshal(payload); err == nil && !av1Packet.Z &&
+		len(av1Packet.OBUElements) > 0 && len(av1Packet.OBUElements[0]) > 0 &&
+		av1Packet.OBUElements[0][0]&0x80 == 0 && (av1Packet.OBUElements[0][0]>>3)&0x0f == obuTypeSequenceHeader {
+		mimeType = mimeTypeAV1
+	}
+
+	switch {
+	case mimeType == "":
+		return
+	case mimeType == mimeTypeVP8 && !i.isVP8, mimeType == mimeTypeAV1 && !i.isAV1:
+		i.logger.Warnf("stream looks like %s, which is not the configured codec", mimeType)
+		i.onCodecDetected(mimeType)
+	}
+	i.codecDetectionEnd = true
+}
+
+// exceedsMaxDuration is called at frame boundaries, once it returns true
+// all subsequent packets are rejected
+func (i *IVFWriter) exceedsMaxDuration(timestamp uint32) bool {
+	if i.maxDuration == 0 || i.clockRate == 0 {
+		return false
+	}
+
+	if i.clockRateDuration+RTPDuration(i.firstTimestamp, timestamp, i.clockRate, i.wrapsAt(timestamp)) > i.maxDuration {
+		i.logger.Warnf("max duration of %s reached, not accepting more frames", i.maxDuration)
+		i.maxDurationReached = true
+		i.currentFrame = nil
+	}
+	return i.maxDurationReached
+}
+
+// rtpDuration converts a number of RTP clock ticks to a duration
+func (i *IVFWriter) rtpDuration(ticks uint32) time.Duration {
+	if i.clockRate == 0 {
+		return 0
+	}
+	return time.Duration(ticks) * time.Second / time.Duration(i.clockRate)
+}
+
+// WriteRTPBatch writes packets in order and stops at the first error.
+// It returns the number of packets that were processed successfully.
+func (i *IVFWriter) WriteRTPBatch(packets []*rtp.Packet) (int, error) {
+	for n, packet := range packets {
+		if err := i.WriteRTP(packet); err != nil {
+			return n, err
+		}
+	}
+	return len(packets), nil
+}
+
+// isKeyFrame detects VP8 key frames and AV1 sequence headers, it returns false for custom codecs
+func (i *IVFWriter) isKeyFrame(frame []byte) bool {
+	switch {
+	case i.isVP8:
+		return isVP8KeyFrame(frame)
+	case i.isAV1:
+		return len(frame) > 0 && (frame[0]>>3)&0x0f == obuTypeSequenceHeader
+	}
+	return false
+}
+
+// parseVP8Dimensions reads the dimensions from the uncompressed header of key frames
+func (i *IVFWriter) parseVP8Dimensions(frame []byte) {
+	if len(frame) < 10 || !isVP8KeyFrame(frame) || frame[3] != 0x9d || frame[4] != 0x01 || frame[5] != 0x2a {
+		return
+	}
+	i.width = uint32(binary.LittleEndian.Uint16(frame[6:]) & 0x3fff)
+	i.height = uint32(binary.LittleEndian.Uint16(frame[8:]) & 0x3fff)
+}
+
 func (i *IVFWriter) FrameDropped() {
+	i.logger.Debugf("frame %d dropped", i.frameCount)
 	i.frameCount++
+	i.dropped++
 }
 
-// Close stops the recording
-func (i *IVFWriter) Close() error {
-	if i.ioWriter == nil {
-		// Returns no error as it may be convenient to call
-		// Close() multiple times
-		return nil
+// IVFStats contains statistics about the recording
+type IVFStats struct {
+	// FrameCount is the number of frames, including the ones reported with FrameDropped
+	FrameCount uint64
+	// LossPausedDuration is the time writing was paused because of WithLossThreshold
+	LossPausedDuration time.Duration
+	// Bytes is the size of the frames written, excluding IVF headers
+	Bytes uint64
+	// Duration is the time between the first and the last frame written, from RTP timestamps
+	Duration time.Duration
+	// Width and Height are parsed from the last VP8 key frame, they are 0 for other codecs
+	Width, Height uint32
+}
+
+// Stats returns statistics about the recording
+func (i *IVFWriter) Stats() IVFStats {
+	return IVFStats{
+		FrameCount:         i.frameCount,
+		LossPausedDuration: i.lossPausedTime(),
+		Bytes:              i.bytes,
+		Duration:           i.writtenDuration(),
+		Width:              i.width,
+		Height:             i.height,
 	}
+}
 
-	defer func() {
-		i.ioWriter = nil
-	}()
+// writtenDuration returns the time between the first and the last frame written
+func (i *IVFWriter) writtenDuration() time.Duration {
+	if i.bytes == 0 {
+		return 0
+	}
+	return i.clockRateDuration + RTPDuration(i.firstTimestamp, i.lastTimestamp, i.clockRate, i.timestampWraps)
+}
 
-	if ws, ok := i.ioWriter.(io.WriteSeeker); ok {
-		// Update header
-		if _, err := ws.Seek(16, 0); err != nil {
+// frameRate returns the average frame rate of the frames written
+func (i *IVFWriter) frameRate() float64 {
+	if i.clockRateDuration == 0 {
+		ticks := int64(i.timestampWraps)<<32 + int64(i.lastTimestamp) - int64(i.firstTimestamp)
+		return float64(i.clockRate) * float64(i.frameCount) / float64(ticks)
+	}
+	return float64(i.frameCount) / i.writtenDuration().Seconds()
+}
+
+// Close stops the recording. It is safe to call Close multiple times,
+// subsequent calls return the error of the first one. See CloseWithResult
+// to also get a summary of the recording.
+func (i *IVFWriter) Close() error {
+	_, err := i.CloseWithResult()
+	return err
+}
+
+func (i *IVFWriter) close() error {
+	if i.syntheticFrame != nil && i.frameCount == 0 && !i.preRolling() {
+		i.syntheticDeadline = time.Time{}
+		if err := i.writeSyntheticFrame(i.lastTimestamp); err != nil {
+			i.finishOutput()
 			return err
 		}
+	}
 
-		num, den := framerate.GetBestMatch(float64(i.clockRate) * float64(i.frameCount) / float64(i.lastTimestamp-i.firstTimestamp))
+	err := i.finishOutput()
 
-		buff := make([]byte, 12)
-		binary.LittleEndian.PutUint32(buff[0:], num)                  // Framerate numerator
-		binary.LittleEndian.PutUint32(buff[4:], den)                  // Framerate denominator
-		binary.LittleEndian.PutUint32(buff[8:], uint32(i.frameCount)) // Frame count
+	if i.chapterSidecar != nil {
+		if chaptersErr := i.writeChapters(); err == nil {
+			err = chaptersErr
+		}
+	}
 
-		if _, err := ws.Write(buff); err != nil {
-			return err
+	return err
+}
+
+// finishOutput updates the header of the current output if it can seek, and closes it
+func (i *IVFWriter) finishOutput() error {
+	var err error
+	if i.async != nil {
+		err = i.async.close()
+		i.async = nil
+	}
+
+	ws, headerUpdated := i.ioWriter.(io.WriteSeeker)
+	if headerUpdated {
+		if headerErr := i.updateHeader(ws); err == nil {
+			err = headerErr
 		}
 	}
+	if checksumErr := i.sumOutput(headerUpdated); err == nil {
+		err = checksumErr
+	}
 
+	if i.finalPath != "" {
+		return i.finalizeFile(i.ioWriter.(*os.File), err)
+	}
 	if closer, ok := i.ioWriter.(io.Closer); ok {
-		return closer.Close()
+		// Close even if the header could not be updated, so the file is not leaked
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+func (i *IVFWriter) updateHeader(ws io.WriteSeeker) error {
+	if _, err := ws.Seek(16, 0); err != nil {
+		return err
+	}
+
+	num, den := framerate.GetBestMatch(i.frameRate())
+
+	buff := make([]byte, 12)
+	binary.LittleEndian.PutUint32(buff[0:], num)                  // Framerate numerator
+	binary.LittleEndian.PutUint32(buff[4:], den)                  // Framerate denominator
+	binary.LittleEndian.PutUint32(buff[8:], uint32(i.frameCount)) // Frame count
+
+	if _, err := ws.Write(buff); err != nil {
+		return err
 	}
+	i.frameRateNum, i.frameRateDen = num, den
 
+	i.logger.Debugf("header updated, %d frames at %d/%d fps", i.frameCount, num, den)
 	return nil
 }
 
 // An Option configures a SampleBuilder.
 type Option func(i *IVFWriter) error
 
-// WithCodec configures if IVFWriter is writing AV1 or VP8 packets to disk
+// WithCodec configures if IVFWriter is writing AV1 or VP8 packets to disk.
+// The mime type is case insensitive, and can omit the "video/" prefix.
 func WithCodec(mimeType string) Option {
 	return func(i *IVFWriter) error {
-		if i.isVP8 || i.isAV1 {
+		if i.isVP8 || i.isAV1 || i.depacketizer != nil {
 			return errCodecAlreadySet
 		}
 
-		switch mimeType {
+		switch normalizeMimeType(mimeType) {
 		case mimeTypeVP8:
 			i.isVP8 = true
 			if i.clockRate == 0 {
@@ -249,6 +873,9 @@ func WithCodec(mimeType string) Option {
 			}
 		case mimeTypeAV1:
 			i.isAV1 = true
+			if i.clockRate == 0 {
+				i.clockRate = defaultAV1ClockRate
+			}
 		default:
 			return errNoSuchCodec
 		}
@@ -257,6 +884,57 @@ func WithCodec(mimeType string) Option {
 	}
 }
 
+// normalizeMimeType returns the canonical form of the mime types supported by the writer,
+// it matches lksdk.NormalizeCodecMime which this package does not depend on
+func normalizeMimeType(mimeType string) string {
+	mimeType = strings.TrimSpace(mimeType)
+	for _, known := range []string{mimeTypeVP8, mimeTypeAV1} {
+		if strings.EqualFold(mimeType, known) || strings.EqualFold(mimeType, strings.TrimPrefix(known, "video/")) {
+			return known
+		}
+	}
+	return mimeType
+}
+
+// WithCustomFOURCC configures IVFWriter to write a codec it does not support natively.
+// Frames are reassembled with depacketizer, and written with the given FOURCC in the header.
+// Custom codecs are not gated on key frames, the stream is written from the first partition head.
+func WithCustomFOURCC(fourcc string, clockRate uint32, depacketizer rtp.Depacketizer) Option {
+	return func(i *IVFWriter) error {
+		if i.isVP8 || i.isAV1 || i.depacketizer != nil {
+			return errCodecAlreadySet
+		} else if len(fourcc) != 4 {
+			return errInvalidFOURCC
+		}
+
+		i.fourcc = fourcc
+		i.clockRate = clockRate
+		i.depacketizer = depacketizer
+		return nil
+	}
+}
+
+// SetClockRate changes the clock rate of the RTP timestamps, when a track is renegotiated with a
+// codec using another clock rate. It must be called between frames, after the last packet of the
+// previous stream was written and before the first one of the new stream, otherwise it fails.
+// The time between the two streams is not accounted for in the duration and frame rate.
+func (i *IVFWriter) SetClockRate(clockRate uint32) error {
+	if i.ioWriter == nil {
+		return errFileNotOpened
+	} else if clockRate == 0 {
+		return errInvalidClockRate
+	} else if i.currentFrame != nil || i.isAV1 && i.fragmentCount != 0 {
+		return errNotFrameBoundary
+	}
+
+	if i.bytes > 0 {
+		i.clockRateDuration = i.writtenDuration()
+		i.clockRateChanged = true
+	}
+	i.clockRate = clockRate
+	return nil
+}
+
 // WithClockRate sets clock rate to ensure proper playback speed
 func WithClockRate(clockRate uint32) Option {
 	return func(i *IVFWriter) error {
@@ -264,3 +942,24 @@ func WithClockRate(clockRate uint32) Option {
 		return nil
 	}
 }
+
+// WithMaxDuration stops accepting frames once the recording, measured from
+// RTP timestamps, is longer than maxDuration. WriteRTP then returns
+// ErrMaxDurationReached, and the file can be finalized with Close.
+func WithMaxDuration(maxDuration time.Duration) Option {
+	return func(i *IVFWriter) error {
+		i.maxDuration = maxDuration
+		return nil
+	}
+}
+
+// WithCodecDetection calls onDetected with the mime type of the codec found in the
+// stream, if it differs from the one the writer was configured with. This happens
+// when a track was renegotiated, and the caller should switch to a new writer.
+// Detection relies on the first key frame or sequence header of the stream.
+func WithCodecDetection(onDetected func(mimeType string)) Option {
+	return func(i *IVFWriter) error {
+		i.onCodecDetected = onDetected
+		return nil
+	}
+}
@@ -2,8 +2,10 @@ package ivfwriter
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
@@ -296,3 +298,277 @@ func TestIVFWriter_AV1(t *testing.T) {
 		assert.NoError(t, writer.Close())
 	})
 }
+
+type failingSeeker struct {
+	bytes.Buffer
+	closeCount int
+}
+
+func (f *failingSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("seek failed")
+}
+
+func (f *failingSeeker) Close() error {
+	f.closeCount++
+	return nil
+}
+
+func TestIVFWriter_CloseIdempotent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{})
+		assert.NoError(t, err)
+
+		assert.NoError(t, writer.Close())
+		assert.NoError(t, writer.Close())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		out := &failingSeeker{}
+		writer, err := NewWith(out)
+		assert.NoError(t, err)
+
+		err = writer.Close()
+		assert.EqualError(t, err, "seek failed")
+		assert.Equal(t, err, writer.Close(), "subsequent Close should return the first error")
+		assert.Equal(t, 1, out.closeCount, "underlying writer should be closed once")
+	})
+}
+
+// newVP8Packet builds a single packet VP8 frame starting a partition
+func newVP8Packet(timestamp uint32, keyframe bool) *rtp.Packet {
+	payload := []byte{0x10, 0x01, 0xff, 0xff}
+	if keyframe {
+		payload[1] = 0x00
+	}
+	return &rtp.Packet{
+		Header:  rtp.Header{Timestamp: timestamp, Marker: true},
+		Payload: payload,
+	}
+}
+
+func TestIVFWriter_MaxDuration(t *testing.T) {
+	t.Run("VP8", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{}, WithMaxDuration(time.Second))
+		assert.NoError(t, err)
+
+		assert.NoError(t, writer.WriteRTP(newVP8Packet(1000, true)))
+		assert.NoError(t, writer.WriteRTP(newVP8Packet(1000+90000, false)))
+		assert.Equal(t, uint64(2), writer.frameCount)
+
+		assert.ErrorIs(t, writer.WriteRTP(newVP8Packet(1000+90001, false)), ErrMaxDurationReached)
+		assert.ErrorIs(t, writer.WriteRTP(newVP8Packet(1000+90001, true)), ErrMaxDurationReached)
+		assert.Equal(t, uint64(2), writer.frameCount)
+		assert.NoError(t, writer.Close())
+	})
+
+	t.Run("AV1", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithMaxDuration(time.Second))
+		assert.NoError(t, err)
+
+		// timestamps wrap around during the recording
+		base := uint32(0xffffff00)
+
+		assert.NoError(t, writer.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: base}, Payload: []byte{0x00, 0x01, 0xff}}))
+		assert.NoError(t, writer.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: base + 90000}, Payload: []byte{0x00, 0x01, 0xff}}))
+		assert.ErrorIs(t, writer.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: base + 90001}, Payload: []byte{0x00, 0x01, 0xff}}), ErrMaxDurationReached)
+		assert.Equal(t, uint64(2), writer.frameCount)
+		assert.NoError(t, writer.Close())
+	})
+}
+
+func TestIVFWriter_WriteRTPBatch(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithMaxDuration(time.Second))
+	assert.NoError(t, err)
+
+	n, err := writer.WriteRTPBatch([]*rtp.Packet{
+		newVP8Packet(0, true),
+		newVP8Packet(3000, false),
+		newVP8Packet(180000, false),
+		newVP8Packet(183000, false),
+	})
+	assert.ErrorIs(t, err, ErrMaxDurationReached)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, uint64(2), writer.frameCount)
+}
+
+func TestIVFWriter_CodecDetection(t *testing.T) {
+	vp8Keyframe := []byte{0x10, 0x00, 0x00, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01}
+	av1SequenceHeader := []byte{0x18, 0x08, 0x00, 0x00}
+
+	t.Run("Mismatch", func(t *testing.T) {
+		var detected []string
+		writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithCodecDetection(func(mimeType string) {
+			detected = append(detected, mimeType)
+		}))
+		assert.NoError(t, err)
+
+		_ = writer.WriteRTP(&rtp.Packet{Payload: vp8Keyframe})
+		_ = writer.WriteRTP(&rtp.Packet{Payload: vp8Keyframe})
+		assert.Equal(t, []string{mimeTypeVP8}, detected)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		var detected []string
+		writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithCodecDetection(func(mimeType string) {
+			detected = append(detected, mimeType)
+		}))
+		assert.NoError(t, err)
+
+		assert.NoError(t, writer.WriteRTP(&rtp.Packet{Payload: av1SequenceHeader}))
+		_ = writer.WriteRTP(&rtp.Packet{Payload: vp8Keyframe})
+		assert.Empty(t, detected)
+	})
+
+	t.Run("VP8 writer", func(t *testing.T) {
+		var detected []string
+		writer, err := NewWith(&bytes.Buffer{}, WithCodecDetection(func(mimeType string) {
+			detected = append(detected, mimeType)
+		}))
+		assert.NoError(t, err)
+
+		_ = writer.WriteRTP(&rtp.Packet{Payload: av1SequenceHeader})
+		assert.Equal(t, []string{mimeTypeAV1}, detected)
+	})
+}
+
+// testDepacketizer treats the first byte of the payload as a start flag
+type testDepacketizer struct{}
+
+func (testDepacketizer) Unmarshal(payload []byte) ([]byte, error) {
+	return payload[1:], nil
+}
+
+func (testDepacketizer) IsPartitionHead(payload []byte) bool {
+	return payload[0] == 1
+}
+
+func (testDepacketizer) IsPartitionTail(marker bool, _ []byte) bool {
+	return marker
+}
+
+func TestIVFWriter_CustomFOURCC(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithCustomFOURCC("AV2", 90000, testDepacketizer{}))
+	assert.ErrorIs(t, err, errInvalidFOURCC)
+	_, err = NewWith(&bytes.Buffer{}, WithCodec(mimeTypeVP8), WithCustomFOURCC("AV02", 90000, testDepacketizer{}))
+	assert.ErrorIs(t, err, errCodecAlreadySet)
+
+	buffer := &bytes.Buffer{}
+	writer, err := NewWith(buffer, WithCustomFOURCC("AV02", 90000, testDepacketizer{}))
+	assert.NoError(t, err)
+
+	assert.NoError(t, writer.WriteRTP(&rtp.Packet{Payload: []byte{0, 0xaa}, Header: rtp.Header{Marker: true}}))
+	assert.NoError(t, writer.WriteRTP(&rtp.Packet{Payload: []byte{1, 0x01}}))
+	assert.NoError(t, writer.WriteRTP(&rtp.Packet{Payload: []byte{0, 0x02}, Header: rtp.Header{Marker: true}}))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, []byte("AV02"), buffer.Bytes()[8:12])
+	assert.Equal(t, []byte{
+		0x2, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0,
+		0x01, 0x02,
+	}, buffer.Bytes()[32:])
+}
+
+func TestIVFWriter_WriteFrame(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	writer, err := NewWith(buffer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, writer.WriteFrame([]byte{0x01}, false, 0))
+	assert.Equal(t, 32, buffer.Len(), "frames before the first key frame are dropped")
+
+	assert.NoError(t, writer.WriteFrame([]byte{0x00}, true, 5))
+	assert.NoError(t, writer.WriteFrame([]byte{0x01}, false, 6))
+	assert.Equal(t, uint64(7), writer.frameCount)
+
+	// RTP frames continue after the last written PTS
+	assert.NoError(t, writer.WriteRTP(newVP8Packet(0, false)))
+	assert.Equal(t, uint64(8), writer.frameCount)
+	assert.Equal(t, []byte{
+		0x1, 0x0, 0x0, 0x0, 0x5, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x00,
+		0x1, 0x0, 0x0, 0x0, 0x6, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x01,
+		0x3, 0x0, 0x0, 0x0, 0x7, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x01, 0xff, 0xff,
+	}, buffer.Bytes()[32:])
+}
+
+func TestIVFWriter_WriteFrameDetect(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x01}, 0))
+	assert.Equal(t, uint64(0), writer.frameCount, "inter frames before the first key frame are dropped")
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x00}, 1))
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x01}, 2))
+	assert.Equal(t, uint64(3), writer.frameCount)
+
+	writer, err = NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x30}, 0))
+	assert.Equal(t, uint64(0), writer.frameCount)
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x08}, 0))
+	assert.Equal(t, uint64(1), writer.frameCount, "AV1 sequence header")
+
+	writer, err = NewWith(&bytes.Buffer{}, WithCustomFOURCC("H264", 90000, &codecs.H264Packet{}))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.WriteFrameDetect([]byte{0x01}, 0))
+	assert.Equal(t, uint64(1), writer.frameCount, "frames of custom codecs are key frames")
+}
+
+func TestIVFWriter_CodecMimeCase(t *testing.T) {
+	for _, mimeType := range []string{"video/av1", "AV1", "video/AV1"} {
+		writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeType))
+		assert.NoError(t, err)
+		assert.True(t, writer.isAV1)
+	}
+
+	_, err := NewWith(&bytes.Buffer{}, WithCodec("video/H264"))
+	assert.ErrorIs(t, err, errNoSuchCodec)
+}
+
+func TestIVFWriter_Stats(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Equal(t, IVFStats{}, writer.Stats())
+
+	// key frame of 1280x720, with horizontal scale bits set
+	keyframe := &rtp.Packet{
+		Header:  rtp.Header{Timestamp: 0, Marker: true},
+		Payload: []byte{0x10, 0x00, 0x00, 0x00, 0x9d, 0x01, 0x2a, 0x00, 0x45, 0xd0, 0x02},
+	}
+	assert.NoError(t, writer.WriteRTP(keyframe))
+	assert.NoError(t, writer.WriteRTP(newVP8Packet(90000, false)))
+
+	assert.Equal(t, IVFStats{
+		FrameCount: 2,
+		Bytes:      13,
+		Duration:   time.Second,
+		Width:      1280,
+		Height:     720,
+	}, writer.Stats())
+}
+
+func TestIVFWriter_SetClockRate(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithMaxDuration(time.Second))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, writer.SetClockRate(0), errInvalidClockRate)
+
+	for idx := uint32(0); idx < 3; idx++ {
+		assert.NoError(t, writer.WriteRTP(newVP8Packet(idx*3000, idx == 0)))
+	}
+
+	partial := newVP8Packet(9000, false)
+	partial.Marker = false
+	assert.NoError(t, writer.WriteRTP(partial))
+	assert.ErrorIs(t, writer.SetClockRate(30000), errNotFrameBoundary)
+	writer.currentFrame = nil
+
+	assert.NoError(t, writer.SetClockRate(30000))
+	for idx := uint32(0); idx < 3; idx++ {
+		assert.NoError(t, writer.WriteRTP(newVP8Packet(500000+idx*1000, false)))
+	}
+	stats := writer.Stats()
+	assert.Equal(t, uint64(6), stats.FrameCount)
+	assert.Equal(t, 133333332*time.Nanosecond, stats.Duration)
+
+	// max duration accounts for the time written with the previous clock rate
+	assert.NoError(t, writer.WriteRTP(newVP8Packet(500000+27000, false)))
+	assert.ErrorIs(t, writer.WriteRTP(newVP8Packet(500000+29000, false)), ErrMaxDurationReached)
+}
@@ -0,0 +1,24 @@
+package ivfwriter
+
+import (
+	"time"
+)
+
+// WithKeyframeTimeout limits how long VP8 packets are discarded while waiting for the first key
+// frame. Once timeout elapsed since the first packet, WriteRTP returns ErrNoKeyframe for every
+// packet discarded, so that the caller can request a key frame or abort the recording. Writing
+// starts normally when a key frame is received.
+func WithKeyframeTimeout(timeout time.Duration) Option {
+	return func(i *IVFWriter) error {
+		i.keyFrameTimeout = timeout
+		return nil
+	}
+}
+
+// keyFrameWaitErr is called when a packet is discarded before the first key frame
+func (i *IVFWriter) keyFrameWaitErr() error {
+	if i.keyFrameTimeout > 0 && i.now().Sub(i.firstPacketAt) >= i.keyFrameTimeout {
+		return ErrNoKeyframe
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_KeyframeTimeout(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{}, WithKeyframeTimeout(time.Second))
+	require.NoError(t, err)
+	now := time.Unix(100, 0)
+	writer.now = func() time.Time { return now }
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, false)))
+	now = now.Add(999 * time.Millisecond)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	now = now.Add(time.Millisecond)
+	require.ErrorIs(t, writer.WriteRTP(newVP8Packet(6000, false)), ErrNoKeyframe)
+	require.ErrorIs(t, writer.WriteRTP(newVP8Packet(9000, false)), ErrNoKeyframe)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(12000, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(15000, false)))
+	require.NoError(t, writer.Close())
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+}
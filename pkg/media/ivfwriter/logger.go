@@ -0,0 +1,20 @@
+package ivfwriter
+
+// Logger is used by IVFWriter to report notable events, such as dropped frames or detected loss
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+
+// WithLogger sets the logger used by IVFWriter, nothing is logged by default
+func WithLogger(logger Logger) Option {
+	return func(i *IVFWriter) error {
+		i.logger = logger
+		return nil
+	}
+}
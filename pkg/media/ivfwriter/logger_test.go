@@ -0,0 +1,35 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testLogger struct {
+	debug, warn []string
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Warnf(format string, args ...interface{}) {
+	l.warn = append(l.warn, fmt.Sprintf(format, args...))
+}
+
+func TestIVFWriter_Logger(t *testing.T) {
+	logger := &testLogger{}
+	writer, err := NewWith(&bytes.Buffer{}, WithLogger(logger))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(1234, true)))
+	writer.FrameDropped()
+	require.Equal(t, []string{
+		"first key frame received, timestamp 1234",
+		"frame 1 dropped",
+	}, logger.debug)
+	require.Empty(t, logger.warn)
+}
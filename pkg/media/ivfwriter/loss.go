@@ -0,0 +1,109 @@
+package ivfwriter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pion/rtp/pkg/frame"
+)
+
+var errInvalidLossThreshold = errors.New("loss ratio must be within (0, 1] and window positive")
+
+// lossMonitor measures packet loss from sequence number gaps over the last window received packets
+type lossMonitor struct {
+	ratio float64
+
+	// number of packets lost right before each of the last received packets
+	gaps  []uint16
+	next  int
+	count int
+	lost  int
+
+	lastSeq uint16
+	started bool
+}
+
+func newLossMonitor(ratio float64, window int) *lossMonitor {
+	return &lossMonitor{
+		ratio: ratio,
+		gaps:  make([]uint16, window),
+	}
+}
+
+// push records a received packet, and returns true when the loss over a full window exceeds the threshold
+func (l *lossMonitor) push(seq uint16) bool {
+	var gap uint16
+	if l.started {
+		diff := seq - l.lastSeq
+		if diff == 0 || diff >= 0x8000 {
+			// duplicate or reordered packet
+			return false
+		}
+		gap = diff - 1
+	}
+	l.started = true
+	l.lastSeq = seq
+
+	if l.count == len(l.gaps) {
+		l.lost -= int(l.gaps[l.next])
+	} else {
+		l.count++
+	}
+	l.gaps[l.next] = gap
+	l.lost += int(gap)
+	l.next = (l.next + 1) % len(l.gaps)
+
+	if l.count < len(l.gaps) {
+		return false
+	}
+	return float64(l.lost)/float64(l.lost+l.count) > l.ratio
+}
+
+// reset forgets the loss history, while keeping the last sequence number
+func (l *lossMonitor) reset() {
+	for j := range l.gaps {
+		l.gaps[j] = 0
+	}
+	l.next, l.count, l.lost = 0, 0, 0
+}
+
+func (i *IVFWriter) pauseOnLoss(timestamp uint32) {
+	i.logger.Warnf("packet loss over threshold, pausing until next key frame")
+	i.lossPaused = true
+	i.lossPausedAt = timestamp
+	i.lossPausedUntil = timestamp
+	i.currentFrame = nil
+	i.av1Frame = frame.AV1{}
+	i.fragmentCount = 0
+}
+
+func (i *IVFWriter) resumeAfterLoss(timestamp uint32) {
+	i.lossPaused = false
+	i.lossPausedDuration += i.rtpDuration(timestamp - i.lossPausedAt)
+	i.lossMonitor.reset()
+	i.logger.Debugf("key frame received, resuming after loss")
+}
+
+// WithLossThreshold stops writing frames when more than ratio of the packets were lost
+// over the last window received packets. Writing resumes at the next key frame, so that
+// the recording does not contain undecodable frames. The time spent paused is reported
+// in IVFStats. Only VP8 and AV1 streams can be paused, since key frames of custom
+// codecs cannot be detected.
+func WithLossThreshold(ratio float64, window int) Option {
+	return func(i *IVFWriter) error {
+		if ratio <= 0 || ratio > 1 || window <= 0 {
+			return errInvalidLossThreshold
+		}
+		i.lossMonitor = newLossMonitor(ratio, window)
+		return nil
+	}
+}
+
+// lossPausedTime returns the time spent paused, including the ongoing pause
+func (i *IVFWriter) lossPausedTime() time.Duration {
+	d := i.lossPausedDuration
+	if i.lossPaused {
+		d += i.rtpDuration(i.lossPausedUntil - i.lossPausedAt)
+	}
+	return d
+}
@@ -0,0 +1,54 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLossMonitor(t *testing.T) {
+	l := newLossMonitor(0.25, 4)
+	for _, seq := range []uint16{65534, 65535, 0, 0, 65535} {
+		require.False(t, l.push(seq), "no loss, duplicates and reordered packets are ignored")
+	}
+	require.False(t, l.push(1), "window is not full yet")
+	require.False(t, l.push(3), "1 lost out of 5")
+	require.True(t, l.push(5), "2 lost out of 6")
+
+	l.reset()
+	require.False(t, l.push(10))
+}
+
+func TestIVFWriter_LossThreshold(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithLossThreshold(0, 10))
+	require.ErrorIs(t, err, errInvalidLossThreshold)
+
+	writer, err := NewWith(&bytes.Buffer{}, WithLossThreshold(0.2, 4))
+	require.NoError(t, err)
+
+	write := func(seq uint16, timestamp uint32, keyframe bool) {
+		packet := newVP8Packet(timestamp, keyframe)
+		packet.SequenceNumber = seq
+		require.NoError(t, writer.WriteRTP(packet))
+	}
+
+	write(0, 0, true)
+	for seq := uint16(1); seq < 4; seq++ {
+		write(seq, uint32(seq)*3000, false)
+	}
+	require.Equal(t, uint64(4), writer.Stats().FrameCount)
+
+	// 6 packets lost out of 10
+	write(10, 30000, false)
+	write(11, 33000, false)
+	write(12, 36000, false)
+	require.Equal(t, uint64(4), writer.Stats().FrameCount)
+	require.Equal(t, 66*time.Millisecond+666666*time.Nanosecond, writer.Stats().LossPausedDuration)
+
+	write(13, 39000, true)
+	write(14, 42000, false)
+	require.Equal(t, uint64(6), writer.Stats().FrameCount)
+	require.Equal(t, 100*time.Millisecond, writer.Stats().LossPausedDuration)
+}
@@ -0,0 +1,22 @@
+package ivfwriter
+
+// WithMaxFrames stops accepting frames once n frames were written. WriteRTP then returns
+// ErrMaxFramesReached, and the file can be finalized with Close. Frames are counted across
+// segments, and the frame completing the count is written whole.
+func WithMaxFrames(n uint64) Option {
+	return func(i *IVFWriter) error {
+		i.maxFrames = n
+		return nil
+	}
+}
+
+// countWrittenFrame is called once a frame was written, it rejects the next frames when the
+// limit set by WithMaxFrames is reached
+func (i *IVFWriter) countWrittenFrame() {
+	i.framesWritten++
+	if i.maxFrames > 0 && i.framesWritten >= i.maxFrames {
+		i.logger.Warnf("max frames of %d reached, not accepting more frames", i.maxFrames)
+		i.maxFramesReached = true
+		i.currentFrame = nil
+	}
+}
@@ -0,0 +1,39 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_MaxFrames(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWith(buf, WithMaxFrames(2))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	require.ErrorIs(t, writer.WriteRTP(newVP8Packet(6000, false)), ErrMaxFramesReached)
+	require.ErrorIs(t, writer.WriteFrame([]byte{1, 2, 3}, true, 3), ErrMaxFramesReached)
+	require.NoError(t, writer.Close())
+
+	require.Len(t, buf.Bytes(), 32+2*(12+3))
+}
+
+func TestIVFWriter_MaxFramesFragmented(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWith(buf, WithMaxFrames(1))
+	require.NoError(t, err)
+
+	first := newVP8Packet(0, true)
+	first.Marker = false
+	require.NoError(t, writer.WriteRTP(first))
+	last := newVP8Packet(0, false)
+	last.Payload[0] = 0x00
+	require.NoError(t, writer.WriteRTP(last))
+	require.ErrorIs(t, writer.WriteRTP(newVP8Packet(3000, false)), ErrMaxFramesReached)
+	require.NoError(t, writer.Close())
+
+	require.Len(t, buf.Bytes(), 32+12+6, "the last frame is written whole")
+}
@@ -0,0 +1,34 @@
+package ivfwriter
+
+// Observer is notified of the internals of IVFWriter, to collect metrics
+type Observer interface {
+	// OnFrameReassembled is called when all fragments of a frame were received. spanTimestamp is
+	// the difference between the RTP timestamps of the last and the first fragment. For AV1, it
+	// is called for every packet that completed at least one OBU, with the size of those OBUs.
+	OnFrameReassembled(frameSize int, fragmentCount int, spanTimestamp uint32)
+}
+
+// WithObserver sets an Observer notified as packets are written
+func WithObserver(observer Observer) Option {
+	return func(i *IVFWriter) error {
+		i.observer = observer
+		return nil
+	}
+}
+
+// addFragment records a packet of the frame being reassembled
+func (i *IVFWriter) addFragment(timestamp uint32, first bool) {
+	if first {
+		i.fragmentCount = 0
+		i.fragmentTimestamp = timestamp
+	}
+	i.fragmentCount++
+}
+
+func (i *IVFWriter) frameReassembled(frameSize int, timestamp uint32) {
+	if i.observer != nil {
+		i.observer.OnFrameReassembled(frameSize, i.fragmentCount, timestamp-i.fragmentTimestamp)
+	}
+	i.frameTimestamp, i.frameFragments = timestamp, i.fragmentCount
+	i.fragmentCount = 0
+}
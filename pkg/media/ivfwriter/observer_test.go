@@ -0,0 +1,43 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+type frameStat struct {
+	size, fragments int
+	span            uint32
+}
+
+type testObserver struct {
+	frames []frameStat
+}
+
+func (o *testObserver) OnFrameReassembled(frameSize int, fragmentCount int, spanTimestamp uint32) {
+	o.frames = append(o.frames, frameStat{frameSize, fragmentCount, spanTimestamp})
+}
+
+func TestIVFWriter_Observer(t *testing.T) {
+	observer := &testObserver{}
+	writer, err := NewWith(&bytes.Buffer{}, WithObserver(observer))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+
+	first := newVP8Packet(3000, false)
+	first.Marker = false
+	require.NoError(t, writer.WriteRTP(first))
+	require.NoError(t, writer.WriteRTP(&rtp.Packet{
+		Header:  rtp.Header{Timestamp: 3010, Marker: true},
+		Payload: []byte{0x00, 0xff, 0xff, 0xff},
+	}))
+
+	require.Equal(t, []frameStat{
+		{size: 3, fragments: 1, span: 0},
+		{size: 6, fragments: 2, span: 10},
+	}, observer.frames)
+}
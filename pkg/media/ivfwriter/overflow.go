@@ -0,0 +1,124 @@
+package ivfwriter
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+var errInvalidQueueSize = errors.New("queue size must be positive")
+
+// OverflowPolicy decides what happens to frames when the queue of an asynchronous writer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the queue to have room, like synchronous writes
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropInterFrames drops the frames that are not key frames while the queue is full
+	OverflowDropInterFrames
+	// OverflowDropToKeyframe drops frames from the first one not fitting in the queue until the
+	// next key frame, so that the recording stays decodable
+	OverflowDropToKeyframe
+)
+
+// WithOverflowPolicy writes frames to the output from a goroutine, through a queue of queueSize frames,
+// so that WriteRTP does not block on a slow output. When the queue is full, frames are handled according
+// to policy. Key frames are never dropped, nor any frame of custom codecs, since their key frames cannot
+// be detected. Dropped frames are counted like with FrameDropped. Errors of the output are returned by
+// the following writes, and Close waits for the queue to be written.
+func WithOverflowPolicy(policy OverflowPolicy, queueSize int) Option {
+	return func(i *IVFWriter) error {
+		if queueSize <= 0 {
+			return errInvalidQueueSize
+		}
+		i.overflowPolicy = policy
+		i.queueSize = queueSize
+		return nil
+	}
+}
+
+// dropOnOverflow returns true if the frame must be dropped because the queue is full
+func (i *IVFWriter) dropOnOverflow(frame []byte) bool {
+	if i.overflowPolicy == OverflowBlock || !i.isVP8 && !i.isAV1 {
+		return false
+	}
+
+	keyFrame := i.isKeyFrame(frame)
+	if i.droppingToKeyframe {
+		if !keyFrame {
+			return true
+		}
+		i.droppingToKeyframe = false
+	}
+	if keyFrame || !i.async.full() {
+		return false
+	}
+
+	if i.overflowPolicy == OverflowDropToKeyframe {
+		i.logger.Warnf("output is too slow, dropping frames until the next key frame")
+		i.droppingToKeyframe = true
+	} else {
+		i.logger.Debugf("output is too slow, dropping frame")
+	}
+	return true
+}
+
+// asyncWriter writes buffers to an io.Writer from a goroutine
+type asyncWriter struct {
+	queue chan []byte
+	done  chan struct{}
+
+	lock sync.Mutex
+	err  error
+}
+
+func newAsyncWriter(w io.Writer, queueSize int) *asyncWriter {
+	a := &asyncWriter{
+		queue: make(chan []byte, queueSize),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(a.done)
+		for b := range a.queue {
+			if a.getErr() != nil {
+				continue
+			}
+			if _, err := w.Write(b); err != nil {
+				a.setErr(err)
+			}
+		}
+	}()
+	return a
+}
+
+func (a *asyncWriter) full() bool {
+	return len(a.queue) == cap(a.queue)
+}
+
+// write queues b, blocking while the queue is full, and returns the first error of the output
+func (a *asyncWriter) write(b []byte) error {
+	if err := a.getErr(); err != nil {
+		return err
+	}
+	a.queue <- b
+	return nil
+}
+
+func (a *asyncWriter) setErr(err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.err = err
+}
+
+func (a *asyncWriter) getErr() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.err
+}
+
+// close waits for the queue to be written
+func (a *asyncWriter) close() error {
+	close(a.queue)
+	<-a.done
+	return a.getErr()
+}
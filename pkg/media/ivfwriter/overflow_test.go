@@ -0,0 +1,94 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter blocks every frame write until released
+type slowWriter struct {
+	lock    sync.Mutex
+	buf     bytes.Buffer
+	writes  int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	w.writes++
+	header := w.writes == 1
+	w.lock.Unlock()
+
+	if !header {
+		w.started <- struct{}{}
+		<-w.release
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestIVFWriter_OverflowPolicy(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithOverflowPolicy(OverflowBlock, 0))
+	require.ErrorIs(t, err, errInvalidQueueSize)
+
+	// before and after are written before and after the output is released
+	run := func(t *testing.T, policy OverflowPolicy, before []bool, after []bool) int {
+		out := &slowWriter{started: make(chan struct{}, 100), release: make(chan struct{})}
+		writer, err := NewWith(out, WithOverflowPolicy(policy, 1))
+		require.NoError(t, err)
+
+		// the first frame is being written, the second one fills the queue
+		require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+		<-out.started
+		require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+
+		released := false
+		timestamp := uint32(6000)
+		write := func(keyframes []bool) {
+			for _, keyframe := range keyframes {
+				if released {
+					// let the queue drain, so that the frame is not dropped
+					require.Eventually(t, func() bool { return len(writer.async.queue) == 0 }, time.Second, time.Millisecond)
+				}
+				require.NoError(t, writer.WriteRTP(newVP8Packet(timestamp, keyframe)))
+				timestamp += 3000
+			}
+		}
+
+		if policy == OverflowBlock {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				write(before)
+			}()
+			close(out.release)
+			<-done
+		} else {
+			write(before)
+			close(out.release)
+		}
+		released = true
+		write(after)
+
+		require.NoError(t, writer.Close())
+		require.Equal(t, uint64(2+len(before)+len(after)), writer.Stats().FrameCount)
+		return (out.buf.Len() - 32) / 15
+	}
+
+	t.Run("block", func(t *testing.T) {
+		require.Equal(t, 4, run(t, OverflowBlock, []bool{false, false}, nil))
+	})
+	t.Run("drop inter frames", func(t *testing.T) {
+		require.Equal(t, 4, run(t, OverflowDropInterFrames, []bool{false}, []bool{false, false}))
+	})
+	t.Run("drop to key frame", func(t *testing.T) {
+		require.Equal(t, 4, run(t, OverflowDropToKeyframe, []bool{false}, []bool{false, true, false}))
+	})
+}
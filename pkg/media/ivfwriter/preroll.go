@@ -0,0 +1,99 @@
+package ivfwriter
+
+import (
+	"errors"
+)
+
+var (
+	errInvalidPreRoll = errors.New("pre-roll must be at least one frame")
+	errPreRollCodec   = errors.New("pre-roll is only supported for VP8")
+)
+
+type preRollFrame struct {
+	data      []byte
+	timestamp uint32
+	fragments int
+}
+
+// preRoll retains the frames since the last key frame, up to size frames
+type preRoll struct {
+	size int
+	// always starts with a key frame when not empty
+	frames []preRollFrame
+
+	triggered bool
+	flushed   bool
+}
+
+func (p *preRoll) push(data []byte, timestamp uint32, fragments int) {
+	if isVP8KeyFrame(data) {
+		p.frames = p.frames[:0]
+	} else if len(p.frames) == 0 {
+		return
+	} else if len(p.frames) == p.size {
+		// the key frame would be evicted, remaining frames cannot be decoded without it
+		p.frames = p.frames[:0]
+		return
+	}
+	p.frames = append(p.frames, preRollFrame{data: data, timestamp: timestamp, fragments: fragments})
+}
+
+// preRolling returns true while frames are buffered instead of written
+func (i *IVFWriter) preRolling() bool {
+	return i.preRoll != nil && !i.preRoll.triggered
+}
+
+// flushPreRoll writes the buffered frames, timestamp is the one of the packet being written
+func (i *IVFWriter) flushPreRoll(timestamp uint32) error {
+	frames := i.preRoll.frames
+	i.preRoll.frames = nil
+	i.preRoll.flushed = true
+
+	if len(frames) == 0 {
+		if i.currentFrame != nil && isVP8KeyFrame(i.currentFrame) {
+			i.setFirstTimestamp(timestamp)
+			return nil
+		}
+		i.logger.Debugf("no key frame in pre-roll, waiting for the next one")
+		i.seenKeyFrame = false
+		i.currentFrame = nil
+		return nil
+	}
+
+	i.logger.Debugf("writing %d pre-roll frames", len(frames))
+	i.setFirstTimestamp(frames[0].timestamp)
+	for _, f := range frames {
+		i.frameTimestamp, i.frameFragments = f.timestamp, f.fragments
+		if err := i.writeFrame(f.data); err != nil {
+			return err
+		}
+		i.setLastTimestamp(f.timestamp)
+	}
+	return nil
+}
+
+// Trigger starts writing a recording configured with WithPreRoll. The next
+// write begins with the frames buffered since the last key frame.
+func (i *IVFWriter) Trigger() {
+	if i.preRoll != nil {
+		i.preRoll.triggered = true
+	}
+}
+
+// WithPreRoll buffers up to frames VP8 frames instead of writing them, until Trigger
+// is called. The recording then starts at the last buffered key frame, so that it is
+// decodable from its first frame. If the key frame is more than frames old, the
+// recording starts at the next key frame. Other codecs are rejected by NewWith.
+func WithPreRoll(frames int) Option {
+	return func(i *IVFWriter) error {
+		if frames <= 0 {
+			return errInvalidPreRoll
+		}
+		i.preRoll = &preRoll{size: frames}
+		return nil
+	}
+}
+
+func isVP8KeyFrame(frame []byte) bool {
+	return len(frame) > 0 && frame[0]&0x01 == 0
+}
@@ -0,0 +1,51 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_PreRoll(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithPreRoll(0))
+	require.ErrorIs(t, err, errInvalidPreRoll)
+	_, err = NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithPreRoll(10))
+	require.ErrorIs(t, err, errPreRollCodec)
+
+	t.Run("starts at last key frame", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer, err := NewWith(buf, WithPreRoll(10))
+		require.NoError(t, err)
+
+		for idx, keyframe := range []bool{true, false, false, true, false} {
+			require.NoError(t, writer.WriteRTP(newVP8Packet(uint32(idx)*3000, keyframe)))
+		}
+		require.Equal(t, uint64(0), writer.Stats().FrameCount)
+		require.Equal(t, 32, buf.Len())
+
+		writer.Trigger()
+		require.NoError(t, writer.WriteRTP(newVP8Packet(15000, false)))
+		require.Equal(t, uint64(3), writer.Stats().FrameCount)
+		require.Equal(t, uint32(9000), writer.firstTimestamp)
+		require.True(t, isVP8KeyFrame(buf.Bytes()[32+12:]))
+	})
+
+	t.Run("key frame evicted", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{}, WithPreRoll(2))
+		require.NoError(t, err)
+
+		for idx, keyframe := range []bool{true, false, false} {
+			require.NoError(t, writer.WriteRTP(newVP8Packet(uint32(idx)*3000, keyframe)))
+		}
+
+		writer.Trigger()
+		require.NoError(t, writer.WriteRTP(newVP8Packet(9000, false)))
+		require.Equal(t, uint64(0), writer.Stats().FrameCount)
+
+		require.NoError(t, writer.WriteRTP(newVP8Packet(12000, true)))
+		require.NoError(t, writer.WriteRTP(newVP8Packet(15000, false)))
+		require.Equal(t, uint64(2), writer.Stats().FrameCount)
+		require.Equal(t, uint32(12000), writer.firstTimestamp)
+	})
+}
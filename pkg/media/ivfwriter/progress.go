@@ -0,0 +1,33 @@
+package ivfwriter
+
+import (
+	"errors"
+	"time"
+)
+
+var errInvalidProgressInterval = errors.New("progress interval must be positive")
+
+// WithProgress calls onProgress from WriteRTP with the current stats, every time the recording,
+// measured from RTP timestamps, grows by interval. With WithSegments, progress is reported for
+// the current segment.
+func WithProgress(interval time.Duration, onProgress func(IVFStats)) Option {
+	return func(i *IVFWriter) error {
+		if interval <= 0 {
+			return errInvalidProgressInterval
+		}
+		i.progressInterval = interval
+		i.nextProgress = interval
+		i.onProgress = onProgress
+		return nil
+	}
+}
+
+func (i *IVFWriter) reportProgress() {
+	duration := i.writtenDuration()
+	if duration < i.nextProgress {
+		return
+	}
+	// a single report when several intervals elapsed, after a gap in the stream
+	i.nextProgress = (duration/i.progressInterval + 1) * i.progressInterval
+	i.onProgress(i.Stats())
+}
@@ -0,0 +1,41 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_Progress(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithProgress(0, func(IVFStats) {}))
+	require.ErrorIs(t, err, errInvalidProgressInterval)
+
+	var reports []IVFStats
+	writer, err := NewWith(&bytes.Buffer{}, WithProgress(time.Second, func(stats IVFStats) {
+		reports = append(reports, stats)
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	for ts := uint32(3000); ts < 90000; ts += 3000 {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(ts, false)))
+	}
+	require.Empty(t, reports)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(90000, false)))
+	require.Len(t, reports, 1)
+	require.Equal(t, time.Second, reports[0].Duration)
+	require.Equal(t, uint64(31), reports[0].FrameCount)
+
+	// a gap in the stream produces a single report
+	require.NoError(t, writer.WriteRTP(newVP8Packet(4*90000, false)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(4*90000+3000, false)))
+	require.Len(t, reports, 2)
+	require.Equal(t, 4*time.Second, reports[1].Duration)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(5*90000, false)))
+	require.Len(t, reports, 3)
+	require.NoError(t, writer.Close())
+}
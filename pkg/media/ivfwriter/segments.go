@@ -0,0 +1,92 @@
+package ivfwriter
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+var errNoSegmentOpener = errors.New("segments are not enabled, see WithSegments")
+
+// SegmentOpener opens the output of the segment with the given index. The first segment,
+// index 0, is the output the writer was created with.
+type SegmentOpener func(index int) (io.Writer, error)
+
+// WithSegments allows splitting the recording with Cut, open is called to create the output of
+// every new segment. Each segment is a complete IVF file, with its own header, PTS starting at 0
+// and maximum duration when WithMaxDuration is set. Stats and chapters are those of the current
+// segment.
+func WithSegments(open SegmentOpener) Option {
+	return func(i *IVFWriter) error {
+		i.openSegment = open
+		return nil
+	}
+}
+
+// WithMaxOpenDuration cuts the recording, as Cut does, once a segment has been open for
+// duration of wall clock time. It limits the size of segments during continuous recordings
+// even when the stream is paused. It requires WithSegments.
+func WithMaxOpenDuration(duration time.Duration) Option {
+	return func(i *IVFWriter) error {
+		i.maxOpenDuration = duration
+		return nil
+	}
+}
+
+// Cut finalizes the current segment at the next key frame, so that every segment is
+// playable on its own, and writes the following frames to a new segment. It returns
+// the index of the segment that will be completed. Streams of custom codecs are cut
+// at the next frame, since their key frames cannot be detected.
+func (i *IVFWriter) Cut() (int, error) {
+	if i.ioWriter == nil {
+		return 0, errFileNotOpened
+	} else if i.openSegment == nil {
+		return 0, errNoSegmentOpener
+	}
+
+	i.cutPending = true
+	return i.segment, nil
+}
+
+// cut switches to the next segment, timestamp is the one of the key frame starting it
+func (i *IVFWriter) cut(timestamp uint32) error {
+	i.cutPending = false
+	if err := i.finishOutput(); err != nil {
+		i.abortSegments(err)
+		return err
+	}
+
+	out, err := i.openSegment(i.segment + 1)
+	if err != nil {
+		i.abortSegments(err)
+		return err
+	}
+	i.logger.Debugf("segment %d completed with %d frames", i.segment, i.frameCount)
+
+	i.segment++
+	i.ioWriter = out
+	i.segmentOpenedAt = i.now()
+	i.frameCount = 0
+	i.startPTS = 0
+	i.keyFrameWritten = false
+	i.bytes = 0
+	i.setFirstTimestamp(timestamp)
+	i.clockRateDuration = 0
+	i.chapters = nil
+	i.nextProgress = i.progressInterval
+	if err = i.writeHeader(); err != nil {
+		return err
+	}
+	if i.queueSize > 0 {
+		i.async = newAsyncWriter(i.ioWriter, i.queueSize)
+	}
+	return nil
+}
+
+// abortSegments stops the recording after the previous output was closed and no new one can be
+// written, Close then returns err
+func (i *IVFWriter) abortSegments(err error) {
+	i.ioWriter = nil
+	i.closeErr = err
+	i.releaseBudget()
+}
@@ -0,0 +1,136 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_Cut(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{})
+	require.NoError(t, err)
+	_, err = writer.Cut()
+	require.ErrorIs(t, err, errNoSegmentOpener)
+
+	segments := []*bytes.Buffer{{}}
+	writer, err = NewWith(segments[0], WithSegments(func(index int) (io.Writer, error) {
+		require.Equal(t, len(segments), index)
+		segments = append(segments, &bytes.Buffer{})
+		return segments[index], nil
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+
+	index, err := writer.Cut()
+	require.NoError(t, err)
+	require.Equal(t, 0, index)
+
+	// the cut is delayed until the next key frame
+	require.NoError(t, writer.WriteRTP(newVP8Packet(6000, false)))
+	require.Len(t, segments, 1)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(9000, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(12000, false)))
+	require.NoError(t, writer.Close())
+
+	require.Len(t, segments, 2)
+	require.Equal(t, 32+3*(12+3), segments[0].Len())
+	require.Equal(t, 32+2*(12+3), segments[1].Len())
+
+	second := segments[1].Bytes()
+	require.Equal(t, "DKIF", string(second[:4]))
+	// PTS restarts with the segment
+	require.Equal(t, uint64(0), binary.LittleEndian.Uint64(second[32+4:]))
+	require.True(t, isVP8KeyFrame(second[32+12:]))
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+}
+
+func TestIVFWriter_MaxOpenDuration(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithMaxOpenDuration(time.Minute))
+	require.ErrorIs(t, err, errNoSegmentOpener)
+
+	count := 1
+	writer, err := NewWith(&bytes.Buffer{}, WithMaxOpenDuration(time.Minute), WithSegments(func(index int) (io.Writer, error) {
+		count++
+		return &bytes.Buffer{}, nil
+	}))
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	writer.now = func() time.Time { return now }
+	writer.segmentOpenedAt = now
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	now = now.Add(time.Minute)
+	// the cut is delayed until the next key frame
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	require.Equal(t, 1, count)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(6000, true)))
+	require.Equal(t, 2, count)
+
+	now = now.Add(59 * time.Second)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(9000, true)))
+	require.Equal(t, 2, count, "the duration is measured from the cut")
+	now = now.Add(time.Second)
+	require.NoError(t, writer.WriteRTP(newVP8Packet(12000, true)))
+	require.Equal(t, 3, count)
+	require.NoError(t, writer.Close())
+}
+
+type failingCloseBuffer struct {
+	bytes.Buffer
+	err error
+}
+
+func (b *failingCloseBuffer) Close() error {
+	return b.err
+}
+
+func TestIVFWriter_CutFailure(t *testing.T) {
+	t.Run("opener", func(t *testing.T) {
+		openErr := errors.New("open failed")
+		budget := &testBudget{}
+		writer, err := NewWith(&bytes.Buffer{}, WithBudget(budget, 16), WithSegments(func(int) (io.Writer, error) {
+			return nil, openErr
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 16, budget.acquired)
+
+		require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+		_, err = writer.Cut()
+		require.NoError(t, err)
+		require.ErrorIs(t, writer.WriteRTP(newVP8Packet(3000, true)), openErr)
+		require.Equal(t, 0, budget.acquired, "the budget is released")
+
+		require.ErrorIs(t, writer.WriteRTP(newVP8Packet(6000, false)), errFileNotOpened)
+		require.ErrorIs(t, writer.Close(), openErr)
+		require.Equal(t, 0, budget.acquired)
+	})
+
+	t.Run("segment output", func(t *testing.T) {
+		closeErr := errors.New("close failed")
+		budget := &testBudget{}
+		opened := 0
+		writer, err := NewWith(&failingCloseBuffer{err: closeErr}, WithBudget(budget, 16), WithSegments(func(int) (io.Writer, error) {
+			opened++
+			return &bytes.Buffer{}, nil
+		}))
+		require.NoError(t, err)
+
+		require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+		_, err = writer.Cut()
+		require.NoError(t, err)
+		require.ErrorIs(t, writer.WriteRTP(newVP8Packet(3000, true)), closeErr)
+		require.Zero(t, opened)
+		require.Equal(t, 0, budget.acquired)
+
+		require.ErrorIs(t, writer.WriteRTP(newVP8Packet(6000, false)), errFileNotOpened)
+		require.ErrorIs(t, writer.Close(), closeErr, "the output is not closed again")
+	})
+}
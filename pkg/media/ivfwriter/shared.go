@@ -0,0 +1,32 @@
+package ivfwriter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPerOutputOption is returned by CheckSharedOptions for options that can't be shared by writers
+var ErrPerOutputOption = errors.New("option can't be shared by several writers")
+
+// CheckSharedOptions returns an error wrapping ErrPerOutputOption if opts can't be used to create
+// several writers, because they name or hold state of a single output: WithAtomicFinalize,
+// WithSegments, WithChapterSidecar and WithChecksum. Writers sharing them would overwrite each
+// other's files or mix their data. Other errors of the options are left to NewWith.
+func CheckSharedOptions(opts ...Option) error {
+	i := &IVFWriter{logger: nopLogger{}}
+	for _, o := range opts {
+		_ = o(i)
+	}
+
+	switch {
+	case i.finalPath != "":
+		return fmt.Errorf("%w: WithAtomicFinalize", ErrPerOutputOption)
+	case i.openSegment != nil:
+		return fmt.Errorf("%w: WithSegments", ErrPerOutputOption)
+	case i.chapterSidecar != nil:
+		return fmt.Errorf("%w: WithChapterSidecar", ErrPerOutputOption)
+	case i.checksum != nil:
+		return fmt.Errorf("%w: WithChecksum", ErrPerOutputOption)
+	}
+	return nil
+}
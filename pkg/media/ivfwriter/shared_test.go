@@ -0,0 +1,24 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSharedOptions(t *testing.T) {
+	require.NoError(t, CheckSharedOptions())
+	require.NoError(t, CheckSharedOptions(WithMaxFrames(10), WithCodec(mimeTypeVP8), WithCodec(mimeTypeAV1)))
+
+	for _, opt := range []Option{
+		WithAtomicFinalize("out.ivf"),
+		WithSegments(func(int) (io.Writer, error) { return &bytes.Buffer{}, nil }),
+		WithChapterSidecar(&bytes.Buffer{}),
+		WithChecksum(sha256.New()),
+	} {
+		require.ErrorIs(t, CheckSharedOptions(WithMaxFrames(10), opt), ErrPerOutputOption)
+	}
+}
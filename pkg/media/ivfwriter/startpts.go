@@ -0,0 +1,12 @@
+package ivfwriter
+
+// WithStartPTS offsets the PTS of every frame by pts, so that a recording continuing a previous
+// one, which ended with pts frames, can be concatenated to it without rewriting timestamps. The
+// frame count in the header and in IVFStats does not include the offset. With WithSegments, the
+// offset only applies to the first segment.
+func WithStartPTS(pts uint64) Option {
+	return func(i *IVFWriter) error {
+		i.startPTS = pts
+		return nil
+	}
+}
@@ -0,0 +1,30 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_StartPTS(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWith(buf, WithStartPTS(100))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0x02}, false, 5))
+	require.NoError(t, writer.Close())
+	require.Equal(t, uint64(6), writer.Stats().FrameCount)
+
+	data := buf.Bytes()
+	offset := 32
+	for _, pts := range []uint64{100, 101, 105} {
+		size := int(binary.LittleEndian.Uint32(data[offset:]))
+		require.Equal(t, pts, binary.LittleEndian.Uint64(data[offset+4:]))
+		offset += 12 + size
+	}
+	require.Equal(t, len(data), offset)
+}
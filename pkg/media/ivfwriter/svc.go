@@ -0,0 +1,35 @@
+package ivfwriter
+
+import (
+	"errors"
+)
+
+const (
+	obuExtensionFlag = 0x04
+	maxSpatialLayers = 4
+)
+
+var errInvalidSpatialLayer = errors.New("AV1 spatial layer must be within [0, 3]")
+
+// WithMaxSpatialLayer only writes the AV1 OBUs of spatial layers up to sid, to record a single
+// layer of an SVC stream. OBUs without an extension header, such as sequence headers, apply to
+// all layers and are always written. The header keeps the default dimensions, since they are
+// not parsed from AV1 sequence headers.
+func WithMaxSpatialLayer(sid int) Option {
+	return func(i *IVFWriter) error {
+		if sid < 0 || sid >= maxSpatialLayers {
+			return errInvalidSpatialLayer
+		}
+		i.filterSpatialLayer = true
+		i.maxSpatialLayer = sid
+		return nil
+	}
+}
+
+// obuSpatialLayer returns the spatial_id from the extension header of an OBU, or 0 without extension
+func obuSpatialLayer(obu []byte) int {
+	if len(obu) < 2 || obu[0]&obuExtensionFlag == 0 {
+		return 0
+	}
+	return int(obu[1]>>3) & 0x03
+}
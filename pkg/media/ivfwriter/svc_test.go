@@ -0,0 +1,36 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_MaxSpatialLayer(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithMaxSpatialLayer(4))
+	require.ErrorIs(t, err, errInvalidSpatialLayer)
+
+	writer, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithMaxSpatialLayer(0))
+	require.NoError(t, err)
+
+	// aggregation header with W=1, an OBU without extension, then an OBU of spatial layer 1
+	obu := func(e byte) *rtp.Packet {
+		return &rtp.Packet{
+			Header:  rtp.Header{Timestamp: 3000, Marker: true},
+			Payload: []byte{0x10, 0x34, e, 0xff},
+		}
+	}
+	require.NoError(t, writer.WriteRTP(&rtp.Packet{Payload: []byte{0x18, 0x08, 0x00, 0x00}}))
+	require.Equal(t, uint64(1), writer.Stats().FrameCount)
+
+	require.NoError(t, writer.WriteRTP(obu(0x08)))
+	require.Equal(t, uint64(1), writer.Stats().FrameCount, "spatial layer 1 is dropped")
+
+	require.NoError(t, writer.WriteRTP(obu(0x00)))
+	require.Equal(t, uint64(2), writer.Stats().FrameCount)
+
+	require.Equal(t, 0, obuSpatialLayer([]byte{0x30, 0x18}))
+	require.Equal(t, 3, obuSpatialLayer([]byte{0x34, 0x18}))
+}
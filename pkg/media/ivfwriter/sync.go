@@ -0,0 +1,59 @@
+package ivfwriter
+
+import (
+	"errors"
+)
+
+var errInvalidSyncInterval = errors.New("sync interval must be at least one frame")
+
+// syncer is implemented by outputs that can be flushed to stable storage, such as *os.File
+type syncer interface {
+	Sync() error
+}
+
+// Sync flushes the frames written so far to stable storage, when the output implements
+// Sync() error like *os.File does. It does nothing for other outputs. With WithOverflowPolicy,
+// Sync first waits for the queued frames to be written. The header is only updated on Close.
+func (i *IVFWriter) Sync() error {
+	if i.ioWriter == nil {
+		return errFileNotOpened
+	}
+	s, ok := i.ioWriter.(syncer)
+	if !ok {
+		return nil
+	}
+
+	i.unsynced = 0
+	if i.async != nil {
+		err := i.async.close()
+		i.async = newAsyncWriter(i.ioWriter, i.queueSize)
+		if err != nil {
+			// keep failing the following writes
+			i.async.setErr(err)
+			return err
+		}
+	}
+	return s.Sync()
+}
+
+// WithSyncEvery calls Sync every frames frames, so that no more than frames frames are lost on
+// a crash or power failure. Syncing blocks until the storage acknowledged the writes, which can
+// take milliseconds on disks: small intervals significantly reduce the throughput of the writer,
+// and with WithOverflowPolicy, prevent it from absorbing slow writes.
+func WithSyncEvery(frames int) Option {
+	return func(i *IVFWriter) error {
+		if frames <= 0 {
+			return errInvalidSyncInterval
+		}
+		i.syncEvery = frames
+		return nil
+	}
+}
+
+func (i *IVFWriter) syncPeriodically() error {
+	i.unsynced++
+	if i.unsynced < i.syncEvery {
+		return nil
+	}
+	return i.Sync()
+}
@@ -0,0 +1,59 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type syncBuffer struct {
+	bytes.Buffer
+	syncs int
+	// size of the buffer on each sync
+	synced []int
+}
+
+func (b *syncBuffer) Sync() error {
+	b.syncs++
+	b.synced = append(b.synced, b.Len())
+	return nil
+}
+
+func TestIVFWriter_Sync(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithSyncEvery(0))
+	require.ErrorIs(t, err, errInvalidSyncInterval)
+
+	writer, err := NewWith(&bytes.Buffer{})
+	require.NoError(t, err)
+	require.NoError(t, writer.Sync(), "outputs that cannot sync are ignored")
+
+	out := &syncBuffer{}
+	writer, err = NewWith(out, WithSyncEvery(2))
+	require.NoError(t, err)
+	for ts := uint32(0); ts < 5*3000; ts += 3000 {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(ts, ts == 0)))
+	}
+	require.Equal(t, 2, out.syncs)
+	require.Equal(t, []int{32 + 2*15, 32 + 4*15}, out.synced)
+
+	require.NoError(t, writer.Sync())
+	require.Equal(t, 3, out.syncs)
+	require.NoError(t, writer.Close())
+	require.ErrorIs(t, writer.Sync(), errFileNotOpened)
+}
+
+func TestIVFWriter_SyncAsync(t *testing.T) {
+	out := &syncBuffer{}
+	writer, err := NewWith(out, WithOverflowPolicy(OverflowBlock, 8))
+	require.NoError(t, err)
+	for ts := uint32(0); ts < 3*3000; ts += 3000 {
+		require.NoError(t, writer.WriteRTP(newVP8Packet(ts, ts == 0)))
+	}
+	require.NoError(t, writer.Sync())
+	require.Equal(t, []int{32 + 3*15}, out.synced, "queued frames are written before syncing")
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(9000, false)))
+	require.NoError(t, writer.Close())
+	require.Equal(t, 32+4*15, out.Len())
+}
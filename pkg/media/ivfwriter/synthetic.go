@@ -0,0 +1,65 @@
+package ivfwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// maxSyntheticFrameSize keeps the first partition of synthetic frames within the 19 bits of its size
+const maxSyntheticFrameSize = 4096
+
+var (
+	errInvalidSyntheticFrame = errors.New("synthetic frame dimensions must be within [1, 4096] and timeout positive")
+	errSyntheticFrameCodec   = errors.New("synthetic first frames are only supported for VP8")
+)
+
+// syntheticVP8KeyFrame builds a VP8 key frame decoding to a uniform grey picture. Both partitions
+// only contain zeros, which a boolean decoder reads as zero bits whatever their probability: the
+// header disables every optional feature and updates no probability, and every macroblock is
+// intra predicted from the frame edges with no residual.
+func syntheticVP8KeyFrame(width, height int) []byte {
+	macroblocks := ((width + 15) / 16) * ((height + 15) / 16)
+	// a few bits per macroblock are read from each partition, give them ample room
+	firstPartitionSize := 128 + 2*macroblocks
+	tokenPartitionSize := 64 + 2*macroblocks
+
+	frame := make([]byte, 10+firstPartitionSize+tokenPartitionSize)
+	// frame tag: key frame, version 0, shown, and the size of the first partition
+	tag := uint32(1<<4 | firstPartitionSize<<5)
+	frame[0], frame[1], frame[2] = byte(tag), byte(tag>>8), byte(tag>>16)
+	frame[3], frame[4], frame[5] = 0x9d, 0x01, 0x2a
+	binary.LittleEndian.PutUint16(frame[6:], uint16(width))
+	binary.LittleEndian.PutUint16(frame[8:], uint16(height))
+	return frame
+}
+
+// WithSyntheticFirstFrame guarantees that VP8 recordings are decodable from their first frame.
+// When no key frame was received timeout after the writer was created, a synthetic key frame
+// of the given dimensions is written before the next frame, which is written as received.
+// When no frame at all was written, it is written on Close so that the file is not empty.
+// The synthetic frame is a uniform grey picture.
+func WithSyntheticFirstFrame(width, height int, timeout time.Duration) Option {
+	return func(i *IVFWriter) error {
+		if width <= 0 || width > maxSyntheticFrameSize || height <= 0 || height > maxSyntheticFrameSize || timeout <= 0 {
+			return errInvalidSyntheticFrame
+		}
+		i.syntheticFrame = syntheticVP8KeyFrame(width, height)
+		i.syntheticTimeout = timeout
+		return nil
+	}
+}
+
+// writeSyntheticFrame writes the synthetic key frame if no key frame was received in time,
+// timestamp is the one of the frame that follows it
+func (i *IVFWriter) writeSyntheticFrame(timestamp uint32) error {
+	if i.syntheticFrame == nil || i.seenKeyFrame || i.now().Before(i.syntheticDeadline) {
+		return nil
+	}
+
+	i.logger.Warnf("no key frame received after %s, writing a synthetic one", i.syntheticTimeout)
+	i.seenKeyFrame = true
+	i.setFirstTimestamp(timestamp)
+	i.frameTimestamp, i.frameFragments = 0, 0
+	return i.writeFrame(i.syntheticFrame)
+}
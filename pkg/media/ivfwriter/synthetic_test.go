@@ -0,0 +1,74 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyntheticVP8KeyFrame(t *testing.T) {
+	frame := syntheticVP8KeyFrame(1280, 720)
+	require.True(t, isVP8KeyFrame(frame))
+	require.Equal(t, []byte{0x9d, 0x01, 0x2a}, frame[3:6])
+	require.Equal(t, uint16(1280), binary.LittleEndian.Uint16(frame[6:]))
+	require.Equal(t, uint16(720), binary.LittleEndian.Uint16(frame[8:]))
+
+	tag := uint32(frame[0]) | uint32(frame[1])<<8 | uint32(frame[2])<<16
+	require.Equal(t, uint32(1), tag>>4&0x01, "frame is shown")
+	firstPartitionSize := int(tag >> 5)
+	require.Less(t, 10+firstPartitionSize, len(frame))
+
+	frame = syntheticVP8KeyFrame(maxSyntheticFrameSize, maxSyntheticFrameSize)
+	tag = uint32(frame[0]) | uint32(frame[1])<<8 | uint32(frame[2])<<16
+	require.Equal(t, len(frame)-10, int(tag>>5)+64+2*256*256, "first partition size does not overflow")
+}
+
+func TestIVFWriter_SyntheticFirstFrame(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithSyntheticFirstFrame(0, 720, time.Second))
+	require.ErrorIs(t, err, errInvalidSyntheticFrame)
+	_, err = NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithSyntheticFirstFrame(1280, 720, time.Second))
+	require.ErrorIs(t, err, errSyntheticFrameCodec)
+
+	t.Run("timeout", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{}, WithSyntheticFirstFrame(320, 240, time.Second))
+		require.NoError(t, err)
+		now := writer.syntheticDeadline.Add(-time.Millisecond)
+		writer.now = func() time.Time { return now }
+
+		require.NoError(t, writer.WriteRTP(newVP8Packet(0, false)))
+		require.Equal(t, uint64(0), writer.Stats().FrameCount, "inter frames are dropped before the timeout")
+
+		now = now.Add(time.Millisecond)
+		require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+		require.NoError(t, writer.WriteRTP(newVP8Packet(6000, false)))
+		require.NoError(t, writer.Close())
+
+		stats := writer.Stats()
+		require.Equal(t, uint64(3), stats.FrameCount)
+		require.Equal(t, uint32(320), stats.Width)
+		require.Equal(t, uint32(240), stats.Height)
+	})
+
+	t.Run("key frame in time", func(t *testing.T) {
+		writer, err := NewWith(&bytes.Buffer{}, WithSyntheticFirstFrame(320, 240, time.Second))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+		writer.now = func() time.Time { return writer.syntheticDeadline.Add(time.Second) }
+		require.NoError(t, writer.WriteRTP(newVP8Packet(3000, false)))
+		require.NoError(t, writer.Close())
+		require.Equal(t, uint64(2), writer.Stats().FrameCount)
+		require.Equal(t, uint32(0), writer.Stats().Width)
+	})
+
+	t.Run("no frames", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer, err := NewWith(buf, WithSyntheticFirstFrame(320, 240, time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		require.Equal(t, uint64(1), writer.Stats().FrameCount)
+		require.Equal(t, 32+12+len(syntheticVP8KeyFrame(320, 240)), buf.Len())
+	})
+}
@@ -0,0 +1,76 @@
+package ivfwriter
+
+import (
+	"errors"
+
+	"github.com/pion/rtp/codecs"
+)
+
+const maxTemporalLayers = 4
+
+var errInvalidTemporalLayer = errors.New("VP8 temporal layer must be within [0, 3]")
+
+// WithMaxTemporalLayer only writes the VP8 frames of temporal layers up to tid, as signaled by the
+// TID of the payload descriptor. Frames without a TID are always written.
+//
+// Frames of a layer above 0 are only written from a layer sync point, a frame with the Y bit set,
+// whose base layer frame was written according to TL0PICIDX. Until then, and after a frame of the
+// layer was dropped, the layer and the ones above it are dropped, because their frames may reference
+// frames missing from the recording. Key frames sync all the layers.
+func WithMaxTemporalLayer(tid int) Option {
+	return func(i *IVFWriter) error {
+		return i.SetMaxTemporalLayer(tid)
+	}
+}
+
+// SetMaxTemporalLayer changes the highest VP8 temporal layer written, see WithMaxTemporalLayer.
+// Lowering it takes effect on the next frame, raising it adds the new layers at their next sync point.
+func (i *IVFWriter) SetMaxTemporalLayer(tid int) error {
+	if tid < 0 || tid >= maxTemporalLayers {
+		return errInvalidTemporalLayer
+	}
+	i.filterTemporalLayer = true
+	i.maxTemporalLayer = tid
+	return nil
+}
+
+// keepTemporalLayer returns true if the frame starting with the packet must be written
+func (i *IVFWriter) keepTemporalLayer(p *codecs.VP8Packet, keyFrame bool) bool {
+	if p.T == 0 {
+		return true
+	}
+
+	tid := int(p.TID)
+	switch {
+	case keyFrame:
+		for l := range i.temporalLayerSynced {
+			i.temporalLayerSynced[l] = true
+		}
+	case tid > i.maxTemporalLayer:
+		i.unsyncTemporalLayers(tid)
+		return false
+	case tid == 0:
+	case p.L == 1 && i.tl0PicIdxWritten && p.TL0PICIDX != i.tl0PicIdx:
+		i.logger.Debugf("base layer frame %d of temporal layer %d missing", p.TL0PICIDX, tid)
+		i.unsyncTemporalLayers(tid)
+		return false
+	case !i.temporalLayerSynced[tid] && p.Y == 0:
+		i.unsyncTemporalLayers(tid)
+		return false
+	default:
+		i.temporalLayerSynced[tid] = true
+	}
+
+	if tid == 0 && p.L == 1 {
+		i.tl0PicIdx = p.TL0PICIDX
+		i.tl0PicIdxWritten = true
+	}
+	return true
+}
+
+// unsyncTemporalLayers drops the temporal layers from tid until their next sync point
+func (i *IVFWriter) unsyncTemporalLayers(tid int) {
+	for l := tid; l < maxTemporalLayers; l++ {
+		i.temporalLayerSynced[l] = false
+	}
+}
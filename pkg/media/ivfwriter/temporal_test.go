@@ -0,0 +1,59 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+// newVP8LayerPacket returns a packet with a payload descriptor carrying TL0PICIDX, TID and the Y bit
+func newVP8LayerPacket(timestamp uint32, keyframe bool, tl0PicIdx uint8, tid uint8, sync bool, start, marker bool) *rtp.Packet {
+	payload := []byte{0x80, 0x60, tl0PicIdx, tid << 6, 0x01, 0xff, 0xff}
+	if start {
+		payload[0] |= 0x10
+	}
+	if sync {
+		payload[3] |= 0x20
+	}
+	if keyframe {
+		payload[4] = 0x00
+	}
+	return &rtp.Packet{
+		Header:  rtp.Header{Timestamp: timestamp, Marker: marker},
+		Payload: payload,
+	}
+}
+
+func TestIVFWriter_MaxTemporalLayer(t *testing.T) {
+	_, err := NewWith(&bytes.Buffer{}, WithMaxTemporalLayer(4))
+	require.ErrorIs(t, err, errInvalidTemporalLayer)
+
+	writer, err := NewWith(&bytes.Buffer{}, WithMaxTemporalLayer(1))
+	require.NoError(t, err)
+
+	write := func(p *rtp.Packet, frames uint64, msg string) {
+		require.NoError(t, writer.WriteRTP(p))
+		require.Equal(t, frames, writer.Stats().FrameCount, msg)
+	}
+
+	write(newVP8LayerPacket(3000, true, 1, 0, false, true, true), 1, "key frame")
+	write(newVP8LayerPacket(6000, false, 1, 2, false, true, false), 1, "layer above the maximum")
+	write(newVP8LayerPacket(6000, false, 1, 2, false, false, true), 1, "rest of the dropped frame")
+	write(newVP8LayerPacket(9000, false, 1, 1, false, true, true), 2, "layer synced by the key frame")
+	write(newVP8LayerPacket(12000, false, 2, 0, false, true, true), 3, "base layer")
+	write(newVP8LayerPacket(15000, false, 1, 1, true, true, true), 3, "base layer frame not written")
+	write(newVP8LayerPacket(18000, false, 2, 1, false, true, true), 3, "layer not synced")
+	write(newVP8LayerPacket(21000, false, 2, 1, true, true, true), 4, "layer sync point")
+
+	require.NoError(t, writer.SetMaxTemporalLayer(2))
+	write(newVP8LayerPacket(24000, false, 2, 2, false, true, true), 4, "added layer not synced")
+	write(newVP8LayerPacket(27000, false, 2, 2, true, true, true), 5, "added layer sync point")
+
+	require.NoError(t, writer.SetMaxTemporalLayer(0))
+	write(newVP8LayerPacket(30000, false, 2, 1, true, true, true), 5, "removed layer")
+	write(newVP8LayerPacket(33000, false, 3, 0, false, true, true), 6, "base layer")
+	write(newVP8Packet(36000, false), 7, "frame without TID")
+	require.NoError(t, writer.Close())
+}
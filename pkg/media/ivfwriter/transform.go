@@ -0,0 +1,15 @@
+package ivfwriter
+
+// FrameTransformer returns the bytes to write for a frame, keyframe is true for VP8 key frames
+// and AV1 sequence headers. Returning an empty frame drops it, and an error is returned by the
+// write. The returned bytes must remain valid for the codec, the writer does not check them.
+type FrameTransformer func(data []byte, keyframe bool) ([]byte, error)
+
+// WithFrameTransformer calls transform on every frame before it is written, such as to watermark
+// frames or remove metadata OBUs. For AV1, frames are OBUs. transform may modify data in place.
+func WithFrameTransformer(transform FrameTransformer) Option {
+	return func(i *IVFWriter) error {
+		i.transformFrame = transform
+		return nil
+	}
+}
@@ -0,0 +1,36 @@
+package ivfwriter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIVFWriter_FrameTransformer(t *testing.T) {
+	var keyframes []bool
+	transformErr := errors.New("transform failed")
+	buf := &bytes.Buffer{}
+	writer, err := NewWith(buf, WithFrameTransformer(func(data []byte, keyframe bool) ([]byte, error) {
+		keyframes = append(keyframes, keyframe)
+		switch data[1] {
+		case 0xaa:
+			return nil, nil
+		case 0xbb:
+			return nil, transformErr
+		}
+		return append(data, 0xff), nil
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.WriteRTP(newVP8Packet(0, true)))
+	require.NoError(t, writer.WriteFrame([]byte{0x01, 0xaa}, false, 1))
+	require.ErrorIs(t, writer.WriteFrame([]byte{0x01, 0xbb}, false, 2), transformErr)
+	require.NoError(t, writer.Close())
+
+	require.Equal(t, []bool{true, false, false}, keyframes)
+	require.Equal(t, uint64(1), writer.Stats().FrameCount, "dropped frames are not counted")
+	require.Equal(t, uint64(4), writer.Stats().Bytes)
+	require.Equal(t, byte(0xff), buf.Bytes()[buf.Len()-1])
+}
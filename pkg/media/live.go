@@ -0,0 +1,264 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+const (
+	ivfFileHeaderSize  = 32
+	ivfFrameHeaderSize = 12
+
+	// frames queued for a live client before it is considered too slow and disconnected
+	liveClientQueueSize = 256
+)
+
+var errLiveReaderTooSlow = errors.New("live reader too slow, frames were dropped")
+
+// LiveRecording is an IVF file that can be served while it is being written, see ServeLive.
+// It is used as the output of an IVFWriter.
+type LiveRecording struct {
+	lock sync.Mutex
+	file *os.File
+	name string
+
+	header  []byte
+	pending []byte
+	// frames since the last key frame
+	gop []byte
+
+	clients  map[*liveClient]struct{}
+	seeked   bool
+	finished bool
+}
+
+type liveClient struct {
+	frames  chan []byte
+	started bool
+	tooSlow bool
+}
+
+// NewLiveRecording creates the file fileName
+func NewLiveRecording(fileName string) (*LiveRecording, error) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &LiveRecording{
+		file:    f,
+		name:    fileName,
+		clients: make(map[*liveClient]struct{}),
+	}, nil
+}
+
+func (l *LiveRecording) Write(p []byte) (int, error) {
+	n, err := l.file.Write(p)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.seeked {
+		l.parse(p[:n])
+	}
+	return n, err
+}
+
+// Seek is used by IVFWriter to update the header on Close, the stream is not parsed anymore afterwards
+func (l *LiveRecording) Seek(offset int64, whence int) (int64, error) {
+	l.lock.Lock()
+	l.seeked = true
+	l.lock.Unlock()
+
+	return l.file.Seek(offset, whence)
+}
+
+// Close closes the file, and ends the streams of live clients
+func (l *LiveRecording) Close() error {
+	l.lock.Lock()
+	l.finished = true
+	for c := range l.clients {
+		close(c.frames)
+		delete(l.clients, c)
+	}
+	l.lock.Unlock()
+
+	return l.file.Close()
+}
+
+// parse splits the written bytes into frames and sends them to clients, must be called with the lock held
+func (l *LiveRecording) parse(p []byte) {
+	if missing := ivfFileHeaderSize - len(l.header); missing > 0 {
+		if missing > len(p) {
+			missing = len(p)
+		}
+		l.header = append(l.header, p[:missing]...)
+		p = p[missing:]
+	}
+	l.pending = append(l.pending, p...)
+
+	for len(l.pending) >= ivfFrameHeaderSize {
+		size := ivfFrameHeaderSize + int(binary.LittleEndian.Uint32(l.pending))
+		if len(l.pending) < size {
+			return
+		}
+		frame := make([]byte, size)
+		copy(frame, l.pending)
+		l.pending = l.pending[size:]
+
+		keyFrame := l.isKeyFrame(frame[ivfFrameHeaderSize:])
+		if keyFrame {
+			l.gop = append(l.gop[:0], frame...)
+		} else if len(l.gop) > 0 {
+			l.gop = append(l.gop, frame...)
+		}
+
+		for c := range l.clients {
+			if !c.started && !keyFrame {
+				continue
+			}
+			c.started = true
+			select {
+			case c.frames <- frame:
+			default:
+				c.tooSlow = true
+				close(c.frames)
+				delete(l.clients, c)
+			}
+		}
+	}
+}
+
+// isKeyFrame detects VP8 key frames and AV1 sequence headers. Streams of other codecs have no
+// key frames, and live clients only receive the header.
+func (l *LiveRecording) isKeyFrame(frame []byte) bool {
+	return isIVFKeyFrame(string(l.header[8:12]), frame)
+}
+
+// isIVFKeyFrame detects key frames of VP8 and AV1 IVF files, from their FourCC
+func isIVFKeyFrame(fourcc string, frame []byte) bool {
+	if len(frame) == 0 {
+		return false
+	}
+	switch fourcc {
+	case "VP80":
+		return frame[0]&0x01 == 0
+	case "AV01":
+		return (frame[0]>>3)&0x0f == 1
+	}
+	return false
+}
+
+func (l *LiveRecording) subscribe() (*liveClient, []byte) {
+	c := &liveClient{
+		frames:  make(chan []byte, liveClientQueueSize),
+		started: len(l.gop) > 0,
+	}
+	l.clients[c] = struct{}{}
+
+	initial := make([]byte, 0, len(l.header)+len(l.gop))
+	initial = append(initial, l.header...)
+	initial = append(initial, l.gop...)
+	return c, initial
+}
+
+func (l *LiveRecording) unsubscribe(c *liveClient) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, ok := l.clients[c]; ok {
+		close(c.frames)
+		delete(l.clients, c)
+	}
+}
+
+// ServeLive streams a recording to an HTTP client. While it is being written, the client receives
+// the IVF header followed by the frames since the last key frame, then new frames as they are
+// written, using chunked transfer encoding. Once the recording is closed, the file is served with
+// support for range requests.
+func ServeLive(w http.ResponseWriter, r *http.Request, recording *LiveRecording) {
+	recording.lock.Lock()
+	if recording.finished {
+		recording.lock.Unlock()
+		http.ServeFile(w, r, recording.name)
+		return
+	}
+	client, initial := recording.subscribe()
+	recording.lock.Unlock()
+	defer recording.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "video/x-ivf")
+	w.Header().Set("Cache-Control", "no-store")
+	flusher, _ := w.(http.Flusher)
+
+	write := func(b []byte) bool {
+		if _, err := w.Write(b); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	if !write(initial) {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame, ok := <-client.frames:
+			if !ok || !write(frame) {
+				return
+			}
+		}
+	}
+}
+
+// NewReader returns a reader streaming the recording, like ServeLive: while it is being written, the
+// reader starts with the IVF header followed by the frames since the last key frame, then new frames
+// as they are written, and returns io.EOF once the recording is closed. A reader which does not keep
+// up with the recording returns an error instead. Once the recording is closed, the file is read.
+// Readers must be closed.
+func (l *LiveRecording) NewReader() (io.ReadCloser, error) {
+	l.lock.Lock()
+	if l.finished {
+		l.lock.Unlock()
+		return os.Open(l.name)
+	}
+	client, initial := l.subscribe()
+	l.lock.Unlock()
+	return &liveReader{recording: l, client: client, buf: initial}, nil
+}
+
+type liveReader struct {
+	recording *LiveRecording
+	client    *liveClient
+	buf       []byte
+}
+
+func (r *liveReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		frame, ok := <-r.client.frames
+		if !ok {
+			r.recording.lock.Lock()
+			defer r.recording.lock.Unlock()
+			if r.client.tooSlow {
+				return 0, errLiveReaderTooSlow
+			}
+			return 0, io.EOF
+		}
+		r.buf = frame
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close unsubscribes the reader, pending reads return io.EOF
+func (r *liveReader) Close() error {
+	r.recording.unsubscribe(r.client)
+	return nil
+}
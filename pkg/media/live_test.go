@@ -0,0 +1,143 @@
+package media
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+func vp8Packet(timestamp uint32, keyframe bool, marker byte) *rtp.Packet {
+	header := byte(0x01)
+	if keyframe {
+		header = 0x00
+	}
+	return &rtp.Packet{
+		Header:  rtp.Header{Timestamp: timestamp, Marker: true},
+		Payload: []byte{0x10, header, marker, marker},
+	}
+}
+
+func TestServeLive(t *testing.T) {
+	recording, err := NewLiveRecording(filepath.Join(t.TempDir(), "live.ivf"))
+	require.NoError(t, err)
+	writer, err := ivfwriter.NewWith(recording)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeLive(w, r, recording)
+	}))
+	defer server.Close()
+
+	require.NoError(t, writer.WriteRTP(vp8Packet(0, true, 1)))
+	require.NoError(t, writer.WriteRTP(vp8Packet(3000, false, 2)))
+	require.NoError(t, writer.WriteRTP(vp8Packet(6000, true, 3)))
+
+	// late joiner starts at the last key frame
+	res, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, "video/x-ivf", res.Header.Get("Content-Type"))
+
+	initial := make([]byte, 32+12+3)
+	_, err = io.ReadFull(res.Body, initial)
+	require.NoError(t, err)
+	require.Equal(t, "DKIF", string(initial[:4]))
+	require.Equal(t, []byte{0x00, 3, 3}, initial[32+12:])
+
+	require.NoError(t, writer.WriteRTP(vp8Packet(9000, false, 4)))
+	frame := make([]byte, 12+3)
+	_, err = io.ReadFull(res.Body, frame)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 4, 4}, frame[12:])
+
+	require.NoError(t, writer.Close())
+	rest, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+
+	// completed recordings support range requests
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", "bytes=0-3")
+	res, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusPartialContent, res.StatusCode)
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "DKIF", string(b))
+}
+
+func TestLiveRecording_NewReader(t *testing.T) {
+	recording, err := NewLiveRecording(filepath.Join(t.TempDir(), "live.ivf"))
+	require.NoError(t, err)
+	writer, err := ivfwriter.NewWith(recording)
+	require.NoError(t, err)
+
+	early, err := recording.NewReader()
+	require.NoError(t, err)
+	defer early.Close()
+
+	require.NoError(t, writer.WriteRTP(vp8Packet(0, true, 1)))
+	require.NoError(t, writer.WriteRTP(vp8Packet(3000, false, 2)))
+	require.NoError(t, writer.WriteRTP(vp8Packet(6000, true, 3)))
+
+	late, err := recording.NewReader()
+	require.NoError(t, err)
+	closed, err := recording.NewReader()
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+
+	require.NoError(t, writer.WriteRTP(vp8Packet(9000, false, 4)))
+	require.NoError(t, writer.Close())
+
+	b, err := io.ReadAll(early)
+	require.NoError(t, err)
+	require.Len(t, b, 32+4*(12+3))
+	require.Equal(t, []byte{0x00, 1, 1}, b[32+12:32+12+3])
+
+	// late readers start at the last key frame
+	b, err = io.ReadAll(late)
+	require.NoError(t, err)
+	require.NoError(t, late.Close())
+	require.Len(t, b, 32+2*(12+3))
+	require.Equal(t, "DKIF", string(b[:4]))
+	require.Equal(t, []byte{0x00, 3, 3}, b[32+12:32+12+3])
+
+	b, err = io.ReadAll(closed)
+	require.NoError(t, err)
+	require.Len(t, b, 32+12+3, "no frames are received once closed")
+
+	// completed recordings are read from the file
+	file, err := recording.NewReader()
+	require.NoError(t, err)
+	defer file.Close()
+	b, err = io.ReadAll(file)
+	require.NoError(t, err)
+	require.Len(t, b, 32+4*(12+3))
+}
+
+func TestLiveRecording_SlowReader(t *testing.T) {
+	recording, err := NewLiveRecording(filepath.Join(t.TempDir(), "live.ivf"))
+	require.NoError(t, err)
+	writer, err := ivfwriter.NewWith(recording)
+	require.NoError(t, err)
+
+	reader, err := recording.NewReader()
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for n := 0; n <= liveClientQueueSize; n++ {
+		require.NoError(t, writer.WriteRTP(vp8Packet(uint32(n)*3000, n == 0, 1)))
+	}
+	_, err = io.ReadAll(reader)
+	require.ErrorIs(t, err, errLiveReaderTooSlow)
+	require.NoError(t, writer.Close())
+}
@@ -0,0 +1,76 @@
+// Package media implements helpers to work with the media writers
+package media
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+// RTPWriter is implemented by writers that take RTP packets, such as ivfwriter.IVFWriter
+type RTPWriter interface {
+	WriteRTP(packet *rtp.Packet) error
+}
+
+// Pacer forwards RTP packets to a RTPWriter, optionally releasing them at the
+// cadence given by their RTP timestamps. This is useful when replaying captured
+// packets, so that the writer sees them as it would see a live stream.
+type Pacer struct {
+	writer    RTPWriter
+	clockRate uint32
+	realTime  bool
+
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	started       bool
+	startTime     time.Time
+	lastTimestamp uint32
+	elapsed       int64
+}
+
+// A PacerOption configures a Pacer.
+type PacerOption func(p *Pacer)
+
+// WithRealTimePacing delays every packet until its RTP timestamp is due. Without it,
+// packets are forwarded as soon as they are written.
+func WithRealTimePacing() PacerOption {
+	return func(p *Pacer) {
+		p.realTime = true
+	}
+}
+
+// NewPacer creates a Pacer writing to writer, clockRate is the RTP clock rate of the stream
+func NewPacer(writer RTPWriter, clockRate uint32, opts ...PacerOption) *Pacer {
+	p := &Pacer{
+		writer:    writer,
+		clockRate: clockRate,
+		now:       time.Now,
+		sleep:     time.Sleep,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// WriteRTP blocks until the packet is due, then forwards it to the writer
+func (p *Pacer) WriteRTP(packet *rtp.Packet) error {
+	if p.realTime && p.clockRate != 0 {
+		if !p.started {
+			p.started = true
+			p.startTime = p.now()
+		} else {
+			// signed difference handles timestamp wraparound and reordered packets
+			p.elapsed += int64(int32(packet.Timestamp - p.lastTimestamp))
+		}
+		p.lastTimestamp = packet.Timestamp
+
+		due := p.startTime.Add(time.Duration(p.elapsed) * time.Second / time.Duration(p.clockRate))
+		if wait := due.Sub(p.now()); wait > 0 {
+			p.sleep(wait)
+		}
+	}
+
+	return p.writer.WriteRTP(packet)
+}
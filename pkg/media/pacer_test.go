@@ -0,0 +1,53 @@
+package media
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+type packetRecorder struct {
+	packets []*rtp.Packet
+}
+
+func (r *packetRecorder) WriteRTP(packet *rtp.Packet) error {
+	r.packets = append(r.packets, packet)
+	return nil
+}
+
+func TestPacer(t *testing.T) {
+	t.Run("passthrough", func(t *testing.T) {
+		rec := &packetRecorder{}
+		p := NewPacer(rec, 90000)
+		p.sleep = func(time.Duration) { t.Fatal("should not sleep") }
+
+		require.NoError(t, p.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: 0}}))
+		require.NoError(t, p.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: 90000}}))
+		require.Len(t, rec.packets, 2)
+	})
+
+	t.Run("real time", func(t *testing.T) {
+		rec := &packetRecorder{}
+		p := NewPacer(rec, 90000, WithRealTimePacing())
+
+		now := time.Unix(0, 0)
+		var slept []time.Duration
+		p.now = func() time.Time { return now }
+		p.sleep = func(d time.Duration) {
+			slept = append(slept, d)
+			now = now.Add(d)
+		}
+
+		// starts close to wraparound to make sure the elapsed time stays monotonic
+		for _, ts := range []uint32{0xffffffff - 2998, 0xffffffff - 2998, 1, 3001} {
+			require.NoError(t, p.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: ts}}))
+		}
+		require.Len(t, rec.packets, 4)
+		require.Equal(t, []time.Duration{
+			time.Second / 30,
+			time.Second / 30,
+		}, slept)
+	})
+}
@@ -0,0 +1,50 @@
+package media
+
+import (
+	"time"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+const (
+	QualityHigh   = "high"
+	QualityMedium = "medium"
+	QualityLow    = "low"
+)
+
+// minimum resolution (shorter side) and bitrate in bits per second of each quality
+var qualityLevels = []struct {
+	label   string
+	minSide uint32
+	minRate float64
+}{
+	{label: QualityHigh, minSide: 720, minRate: 1_000_000},
+	{label: QualityMedium, minSide: 360, minRate: 300_000},
+}
+
+// QualityLabel labels a recording as high, medium or low quality from its resolution and
+// average bitrate in bits per second. A recording gets the highest label whose minimum
+// resolution and bitrate it meets. When the resolution is unknown, width and height are 0,
+// and only the bitrate is used.
+func QualityLabel(width, height uint32, bitrate float64) string {
+	side := width
+	if height < side {
+		side = height
+	}
+
+	for _, level := range qualityLevels {
+		if (side == 0 || side >= level.minSide) && bitrate >= level.minRate {
+			return level.label
+		}
+	}
+	return QualityLow
+}
+
+// StatsQualityLabel labels a recording from the stats of its writer
+func StatsQualityLabel(stats ivfwriter.IVFStats) string {
+	var bitrate float64
+	if stats.Duration > 0 {
+		bitrate = float64(stats.Bytes*8) / (float64(stats.Duration) / float64(time.Second))
+	}
+	return QualityLabel(stats.Width, stats.Height, bitrate)
+}
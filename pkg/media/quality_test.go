@@ -0,0 +1,30 @@
+package media
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+func TestQualityLabel(t *testing.T) {
+	require.Equal(t, QualityHigh, QualityLabel(1280, 720, 2_000_000))
+	require.Equal(t, QualityHigh, QualityLabel(720, 1280, 2_000_000))
+	require.Equal(t, QualityMedium, QualityLabel(1280, 720, 500_000))
+	require.Equal(t, QualityMedium, QualityLabel(640, 360, 2_000_000))
+	require.Equal(t, QualityLow, QualityLabel(320, 180, 2_000_000))
+	require.Equal(t, QualityLow, QualityLabel(1280, 720, 100_000))
+
+	// unknown resolution
+	require.Equal(t, QualityHigh, QualityLabel(0, 0, 1_500_000))
+	require.Equal(t, QualityLow, QualityLabel(0, 0, 0))
+
+	require.Equal(t, QualityMedium, StatsQualityLabel(ivfwriter.IVFStats{
+		Bytes:    100_000,
+		Duration: 2 * time.Second,
+		Width:    640,
+		Height:   480,
+	}))
+}
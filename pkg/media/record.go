@@ -0,0 +1,476 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+
+	lksdk "github.com/livekit/server-sdk-go"
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+	"github.com/livekit/server-sdk-go/pkg/samplebuilder"
+)
+
+const (
+	defaultManifestName = "manifest.json"
+
+	// packets kept by the sample builders to reorder late packets
+	maxVideoLate = 1000
+	maxAudioLate = 200
+)
+
+var errUnsupportedRecordCodec = errors.New("codec cannot be recorded")
+
+// RecordOption configures RecordRoom
+type RecordOption func(o *recordOptions)
+
+type recordOptions struct {
+	manifestName   string
+	indexName      string
+	ivfOptions     []ivfwriter.Option
+	connectOptions []lksdk.ConnectOption
+	redEnabled     bool
+	redPrimaryPT   uint8
+}
+
+// WithManifestName changes the name of the manifest written in the output directory, manifest.json by default
+func WithManifestName(name string) RecordOption {
+	return func(o *recordOptions) {
+		o.manifestName = name
+	}
+}
+
+// WithIVFOptions configures the writers of VP8 and AV1 tracks. The codec is always set from the track.
+// The options are shared by the writers of all tracks, options of a single output are rejected by
+// StartRecording and RecordRoom, see ivfwriter.CheckSharedOptions.
+func WithIVFOptions(opts ...ivfwriter.Option) RecordOption {
+	return func(o *recordOptions) {
+		o.ivfOptions = append(o.ivfOptions, opts...)
+	}
+}
+
+// WithConnectOptions configures the connection to the room
+func WithConnectOptions(opts ...lksdk.ConnectOption) RecordOption {
+	return func(o *recordOptions) {
+		o.connectOptions = append(o.connectOptions, opts...)
+	}
+}
+
+// RecordingManifest describes the files written by RecordRoom
+type RecordingManifest struct {
+	Room      string           `json:"room"`
+	StartedAt time.Time        `json:"started_at"`
+	EndedAt   time.Time        `json:"ended_at"`
+	Tracks    []TrackRecording `json:"tracks"`
+}
+
+// TrackRecording describes the recording of a track. File is empty when the track could not
+// be recorded, Error then tells why. Frames and Duration are only set for IVF files, and DroppedFrames
+// counts the frames larger than allowed by ivfwriter.WithBudget, which are skipped. SenderStartedAt
+// is the wall clock time of the sender at the first packet written, it is set once a RTCP sender
+// report is received and lets frames be aligned with events logged by other clocks.
+type TrackRecording struct {
+	TrackSID            string        `json:"track_sid"`
+	ParticipantSID      string        `json:"participant_sid"`
+	ParticipantIdentity string        `json:"participant_identity"`
+	Name                string        `json:"name,omitempty"`
+	Kind                string        `json:"kind"`
+	MimeType            string        `json:"mime_type"`
+	File                string        `json:"file,omitempty"`
+	Frames              uint64        `json:"frames,omitempty"`
+	Duration            time.Duration `json:"duration,omitempty"`
+	DroppedFrames       uint64        `json:"dropped_frames,omitempty"`
+	SenderStartedAt     *time.Time    `json:"sender_started_at,omitempty"`
+	Error               string        `json:"error,omitempty"`
+}
+
+// RecordRoom joins the room at url as a subscriber and records every track it subscribes to in
+// outDir: VP8 and AV1 tracks in IVF files, Opus tracks in Ogg files, as well as RED tracks with
+// WithRED. Other codecs are listed in the manifest with an error. A TrackIndex of the files is also
+// written. Recording stops when ctx is done or the room is left, for instance when it is finished.
+// The writers are then closed and the manifest is written to outDir, before RecordRoom returns.
+// Both ways of stopping are successful, errors are only returned when the room cannot be joined
+// or the manifest cannot be written. See StartRecording to monitor the recording.
+func RecordRoom(ctx context.Context, url, token, outDir string, opts ...RecordOption) error {
+	s, err := StartRecording(url, token, outDir, opts...)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-s.Done():
+	}
+	return s.Stop()
+}
+
+// StartRecording joins the room at url and records it like RecordRoom, without waiting for the
+// recording to end. The recording is stopped with Stop, which must be called once the room is left.
+func StartRecording(url, token, outDir string, opts ...RecordOption) (*RecordingSession, error) {
+	o := recordOptions{
+		manifestName: defaultManifestName,
+		indexName:    defaultIndexName,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := ivfwriter.CheckSharedOptions(o.ivfOptions...); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, err
+	}
+
+	s := newRecordingSession(outDir, o)
+	room, err := lksdk.ConnectToRoomWithToken(url, token, &lksdk.RoomCallback{
+		OnDisconnected: s.leave,
+		ParticipantCallback: lksdk.ParticipantCallback{
+			OnTrackSubscribed: s.onTrackSubscribed,
+		},
+	}, o.connectOptions...)
+	if err != nil {
+		return nil, err
+	}
+	s.room = room
+	s.setRoom(room.SID(), room.Name())
+	return s, nil
+}
+
+// RecordingSession is the recording of a room started with StartRecording
+type RecordingSession struct {
+	outDir string
+	opts   recordOptions
+	room   *lksdk.Room
+
+	leaveOnce sync.Once
+	left      chan struct{}
+	stopOnce  sync.Once
+	stopErr   error
+
+	lock      sync.Mutex
+	roomSID   string
+	manifest  RecordingManifest
+	recorders []*trackRecorder
+	closed    bool
+}
+
+func newRecordingSession(outDir string, opts recordOptions) *RecordingSession {
+	return &RecordingSession{
+		outDir: outDir,
+		opts:   opts,
+		left:   make(chan struct{}),
+		manifest: RecordingManifest{
+			StartedAt: time.Now(),
+			Tracks:    []TrackRecording{},
+		},
+	}
+}
+
+func (s *RecordingSession) setRoom(sid, name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.roomSID = sid
+	s.manifest.Room = name
+}
+
+// Done returns a channel closed when the room is left, for instance when it is finished
+func (s *RecordingSession) Done() <-chan struct{} {
+	return s.left
+}
+
+// Stop leaves the room, waits for the writers to be closed and writes the manifest and the index.
+// It is safe to call Stop multiple times, subsequent calls return the error of the first one.
+func (s *RecordingSession) Stop() error {
+	s.stopOnce.Do(func() {
+		if s.room != nil {
+			s.room.Disconnect()
+		}
+		s.stopErr = s.close()
+	})
+	return s.stopErr
+}
+
+func (s *RecordingSession) leave() {
+	s.leaveOnce.Do(func() {
+		close(s.left)
+	})
+}
+
+func (s *RecordingSession) onTrackSubscribed(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	entry := TrackRecording{
+		TrackSID:            pub.SID(),
+		ParticipantSID:      rp.SID(),
+		ParticipantIdentity: rp.Identity(),
+		Name:                pub.Name(),
+		Kind:                track.Kind().String(),
+		MimeType:            track.Codec().MimeType,
+	}
+	readRTP := func() (*rtp.Packet, error) {
+		packet, _, err := track.ReadRTP()
+		return packet, err
+	}
+	wallclock := NewWallclockMapper(track.Codec().ClockRate)
+	pub.OnRTCP(func(packet rtcp.Packet) {
+		if sr, ok := packet.(*rtcp.SenderReport); ok && sr.SSRC == uint32(track.SSRC()) {
+			wallclock.Observe(sr)
+		}
+	})
+	s.addTrack(entry, pub.TrackInfo(), track.Codec().ClockRate, track.Codec().Channels, readRTP, func() {
+		rp.WritePLI(track.SSRC())
+	}, wallclock)
+}
+
+// addTrack starts recording a track, whose packets are read with readRTP until it fails. The codec
+// is checked against the type of info, unless it is nil. wallclock maps their timestamps to the clock of the sender, it may be nil.
+func (s *RecordingSession) addTrack(entry TrackRecording, info *livekit.TrackInfo, clockRate uint32, channels uint16,
+	readRTP func() (*rtp.Packet, error), requestKeyFrame func(), wallclock *WallclockMapper) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return
+	}
+
+	var r *trackRecorder
+	err := lksdk.ValidateTrackCodec(info, entry.MimeType)
+	if info == nil || err == nil {
+		r, err = newTrackRecorder(s.outDir, entry, clockRate, channels, s.opts, requestKeyFrame)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		s.manifest.Tracks = append(s.manifest.Tracks, entry)
+		return
+	}
+	r.info = info
+	r.wallclock = wallclock
+	s.recorders = append(s.recorders, r)
+	go r.run(readRTP)
+}
+
+// close waits for the recorders to finish, and writes the manifest and the index
+func (s *RecordingSession) close() error {
+	s.lock.Lock()
+	s.closed = true
+	recorders := s.recorders
+	s.lock.Unlock()
+
+	index := TrackIndex{
+		Version: TrackIndexVersion,
+		Tracks:  []TrackIndexEntry{},
+	}
+	for _, r := range recorders {
+		<-r.done
+		s.manifest.Tracks = append(s.manifest.Tracks, r.entry)
+		if r.entry.Error == "" {
+			index.Tracks = append(index.Tracks, r.indexEntry(s.roomSID))
+		}
+	}
+	s.manifest.EndedAt = time.Now()
+
+	if err := writeJSON(filepath.Join(s.outDir, s.opts.manifestName), &s.manifest); err != nil {
+		return err
+	}
+	return writeJSON(filepath.Join(s.outDir, s.opts.indexName), &index)
+}
+
+func writeJSON(fileName string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fileName, data, 0644)
+}
+
+// rtpWriteCloser is implemented by the writers of pion and IVFWriter
+type rtpWriteCloser interface {
+	WriteRTP(packet *rtp.Packet) error
+	Close() error
+}
+
+type trackRecorder struct {
+	sb     *samplebuilder.SampleBuilder
+	writer rtpWriteCloser
+	ivf    *ivfwriter.IVFWriter
+	red    *redDecoder
+
+	// guards the writer and the fields updated as packets are written, for Stats
+	lock sync.Mutex
+
+	entry TrackRecording
+	info  *livekit.TrackInfo
+	done  chan struct{}
+
+	// wall clock time and RTP timestamps of the packets written
+	clockRate      uint32
+	startedAt      time.Time
+	firstTimestamp uint32
+	lastTimestamp  uint32
+	timestampWraps int
+	result         ivfwriter.CloseResult
+	wallclock      *WallclockMapper
+
+	// packets and payload bytes written
+	packets uint64
+	bytes   uint64
+}
+
+func newTrackRecorder(outDir string, entry TrackRecording, clockRate uint32, channels uint16,
+	opts recordOptions, requestKeyFrame func()) (*trackRecorder, error) {
+	r := &trackRecorder{
+		entry:     entry,
+		done:      make(chan struct{}),
+		clockRate: clockRate,
+	}
+	name := recordingFileName(entry)
+
+	var err error
+	mime := lksdk.NormalizeCodecMime(entry.MimeType)
+	switch {
+	case mime == webrtc.MimeTypeVP8, mime == webrtc.MimeTypeAV1:
+		var depacketizer rtp.Depacketizer = &codecs.VP8Packet{}
+		if mime == webrtc.MimeTypeAV1 {
+			depacketizer = &av1Depacketizer{}
+		}
+		r.sb = samplebuilder.New(maxVideoLate, depacketizer, clockRate, samplebuilder.WithPacketDroppedHandler(requestKeyFrame))
+		r.entry.File = name + ".ivf"
+		ivfOptions := append([]ivfwriter.Option{ivfwriter.WithCodec(mime)}, opts.ivfOptions...)
+		r.ivf, err = ivfwriter.New(filepath.Join(outDir, r.entry.File), ivfOptions...)
+		r.writer = r.ivf
+
+	case mime == webrtc.MimeTypeOpus, lksdk.CodecMimeEqual(mime, mimeTypeRED):
+		if mime != webrtc.MimeTypeOpus {
+			if !opts.redEnabled {
+				return nil, fmt.Errorf("%w: %s, see WithRED", errUnsupportedRecordCodec, entry.MimeType)
+			}
+			r.red = &redDecoder{primaryPT: opts.redPrimaryPT}
+		}
+		r.sb = samplebuilder.New(maxAudioLate, &codecs.OpusPacket{}, clockRate)
+		r.entry.File = name + ".ogg"
+		r.writer, err = oggwriter.New(filepath.Join(outDir, r.entry.File), clockRate, channels)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedRecordCodec, entry.MimeType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// av1Depacketizer adds the partition checks used by SampleBuilder to codecs.AV1Packet.
+// A frame starts with a packet whose first OBU is not a continuation (Z bit unset).
+type av1Depacketizer struct {
+	codecs.AV1Packet
+}
+
+func (*av1Depacketizer) IsPartitionHead(payload []byte) bool {
+	return len(payload) > 0 && payload[0]&0x80 == 0
+}
+
+func (*av1Depacketizer) IsPartitionTail(marker bool, _ []byte) bool {
+	return marker
+}
+
+func (r *trackRecorder) run(readRTP func() (*rtp.Packet, error)) {
+	defer close(r.done)
+
+	var err error
+	for err == nil {
+		packet, readErr := readRTP()
+		if readErr != nil {
+			break
+		}
+		if r.red == nil {
+			r.sb.Push(packet)
+		} else {
+			for _, p := range r.red.decode(packet) {
+				r.sb.Push(p)
+			}
+		}
+		err = r.writePackets(r.sb.PopPackets())
+	}
+	if err == nil {
+		err = r.writePackets(r.sb.ForcePopPackets())
+	}
+	if errors.Is(err, ivfwriter.ErrMaxDurationReached) {
+		err = nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.ivf != nil {
+		var closeErr error
+		r.result, closeErr = r.ivf.CloseWithResult()
+		r.entry.Frames = r.result.FrameCount
+		r.entry.Duration = r.result.Duration
+		if err == nil {
+			err = closeErr
+		}
+	} else if closeErr := r.writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		r.entry.Error = err.Error()
+	}
+}
+
+func (r *trackRecorder) writePackets(packets []*rtp.Packet) error {
+	if len(packets) == 0 {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, packet := range packets {
+		if err := r.writer.WriteRTP(packet); errors.Is(err, ivfwriter.ErrFrameTooLarge) {
+			// only this frame is lost, the recording goes on
+			r.entry.DroppedFrames++
+		} else if err != nil {
+			return err
+		}
+		r.packets++
+		r.bytes += uint64(len(packet.Payload))
+
+		if r.startedAt.IsZero() {
+			r.startedAt = time.Now()
+			r.firstTimestamp = packet.Timestamp
+		} else if packet.Timestamp < r.lastTimestamp && r.lastTimestamp-packet.Timestamp > 1<<31 {
+			r.timestampWraps++
+		}
+		r.lastTimestamp = packet.Timestamp
+	}
+
+	// resolved with the first sender report, close to the start of the recording
+	if r.wallclock != nil && r.entry.SenderStartedAt == nil && !r.startedAt.IsZero() {
+		if startedAt, ok := r.wallclock.ToWallclock(r.firstTimestamp); ok {
+			r.entry.SenderStartedAt = &startedAt
+		}
+	}
+	return nil
+}
+
+// recordingFileName returns the name of the file of a track, without extension. Characters
+// other than letters, digits, dots, dashes and underscores are replaced by underscores.
+func recordingFileName(entry TrackRecording) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, entry.ParticipantIdentity+"-"+entry.TrackSID)
+}
@@ -0,0 +1,156 @@
+package media
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+func TestRecordingSession(t *testing.T) {
+	outDir := t.TempDir()
+	s := newRecordingSession(outDir, recordOptions{manifestName: defaultManifestName, indexName: defaultIndexName})
+	s.setRoom("RM_1", "room")
+
+	var packets []*rtp.Packet
+	for j := 0; j < 3; j++ {
+		payload := []byte{0x10, 0x01, 0xff, 0xff}
+		if j == 0 {
+			payload[1] = 0x00
+		}
+		packets = append(packets, &rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(j + 1), Timestamp: uint32(j) * 3000, Marker: true},
+			Payload: payload,
+		})
+	}
+	readRTP := func() (*rtp.Packet, error) {
+		if len(packets) == 0 {
+			return nil, io.EOF
+		}
+		packet := packets[0]
+		packets = packets[1:]
+		return packet, nil
+	}
+
+	wallclock := NewWallclockMapper(90000)
+	wallclock.Observe(&rtcp.SenderReport{NTPTime: 0xe5b7_a800_8000_0000, RTPTime: 3000})
+	s.addTrack(TrackRecording{
+		TrackSID:            "TR_video",
+		ParticipantIdentity: "user/1",
+		Kind:                "video",
+		MimeType:            "video/VP8",
+	}, nil, 90000, 0, readRTP, func() {}, wallclock)
+	s.addTrack(TrackRecording{
+		TrackSID:            "TR_mismatch",
+		ParticipantIdentity: "user/1",
+		Kind:                "audio",
+		MimeType:            "video/VP8",
+	}, &livekit.TrackInfo{Sid: "TR_mismatch", Type: livekit.TrackType_AUDIO}, 90000, 0, readRTP, func() {}, nil)
+	s.addTrack(TrackRecording{
+		TrackSID:            "TR_h264",
+		ParticipantIdentity: "user/1",
+		Kind:                "video",
+		MimeType:            "video/H264",
+	}, nil, 90000, 0, readRTP, func() {}, nil)
+	require.NoError(t, s.close())
+
+	data, err := ioutil.ReadFile(filepath.Join(outDir, defaultManifestName))
+	require.NoError(t, err)
+	var manifest RecordingManifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Equal(t, "room", manifest.Room)
+	require.Len(t, manifest.Tracks, 3)
+
+	mismatch := manifest.Tracks[0]
+	require.Equal(t, "TR_mismatch", mismatch.TrackSID)
+	require.Empty(t, mismatch.File)
+	require.Contains(t, mismatch.Error, "does not match")
+
+	unsupported := manifest.Tracks[1]
+	require.Equal(t, "TR_h264", unsupported.TrackSID)
+	require.Empty(t, unsupported.File)
+	require.NotEmpty(t, unsupported.Error)
+
+	video := manifest.Tracks[2]
+	require.Equal(t, "user_1-TR_video.ivf", video.File)
+	require.Empty(t, video.Error)
+	require.Equal(t, uint64(3), video.Frames)
+	require.Equal(t, 2*time.Second/30, video.Duration)
+	require.NotNil(t, video.SenderStartedAt)
+	require.True(t, time.Unix(1645029760, 5e8).Add(-time.Second/30).Equal(*video.SenderStartedAt))
+
+	f, err := os.Open(filepath.Join(outDir, video.File))
+	require.NoError(t, err)
+	defer f.Close()
+	format, err := DetectContainer(f)
+	require.NoError(t, err)
+	require.Equal(t, ContainerIVF, format)
+
+	s.addTrack(TrackRecording{TrackSID: "TR_late", MimeType: "video/VP8"}, nil, 90000, 0, readRTP, func() {}, nil)
+	require.Len(t, s.recorders, 1, "tracks are not recorded once closed")
+}
+
+func TestNewTrackRecorder_MimeVariants(t *testing.T) {
+	for _, mime := range []string{"video/VP8", "VP8", "video/vp8; foo=bar"} {
+		outDir := t.TempDir()
+		r, err := newTrackRecorder(outDir, TrackRecording{
+			TrackSID:            "TR_video",
+			ParticipantIdentity: "user",
+			MimeType:            mime,
+		}, 90000, 0, recordOptions{}, func() {})
+		require.NoError(t, err, mime)
+		require.Equal(t, "user-TR_video.ivf", r.entry.File)
+		require.NoError(t, r.writer.Close())
+	}
+}
+
+func TestTrackRecorder_DroppedFrames(t *testing.T) {
+	opts := recordOptions{ivfOptions: []ivfwriter.Option{ivfwriter.WithBudget(NewBudget(0, 0), 8)}}
+	r, err := newTrackRecorder(t.TempDir(), TrackRecording{
+		TrackSID:            "TR_video",
+		ParticipantIdentity: "user",
+		MimeType:            "video/VP8",
+	}, 90000, 0, opts, func() {})
+	require.NoError(t, err)
+
+	var packets []*rtp.Packet
+	for j, size := range []int{4, 16, 4} {
+		payload := make([]byte, size)
+		payload[0] = 0x10
+		if j > 0 {
+			payload[1] = 0x01
+		}
+		packets = append(packets, &rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(j + 1), Timestamp: uint32(j) * 3000, Marker: true},
+			Payload: payload,
+		})
+	}
+	readRTP := func() (*rtp.Packet, error) {
+		if len(packets) == 0 {
+			return nil, io.EOF
+		}
+		packet := packets[0]
+		packets = packets[1:]
+		return packet, nil
+	}
+	r.run(readRTP)
+
+	require.Empty(t, r.entry.Error)
+	require.Equal(t, uint64(1), r.entry.DroppedFrames)
+	require.Equal(t, uint64(2), r.entry.Frames, "the frames after the dropped one are recorded")
+}
+
+func TestStartRecording_PerOutputOptions(t *testing.T) {
+	_, err := StartRecording("ws://localhost", "token", t.TempDir(), WithIVFOptions(ivfwriter.WithAtomicFinalize("out.ivf")))
+	require.ErrorIs(t, err, ivfwriter.ErrPerOutputOption)
+}
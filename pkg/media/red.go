@@ -0,0 +1,113 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/pion/rtp"
+)
+
+// mimeTypeRED is the mime type of RED (RFC 2198) tracks, which pion does not define
+const mimeTypeRED = "audio/red"
+
+var errMalformedRED = errors.New("malformed RED payload")
+
+// WithRED records RED tracks, carrying Opus with redundancy as used by LiveKit clients, as Opus
+// tracks. primaryPT is the payload type of Opus within the RED payloads, blocks of other payload
+// types are ignored. The primary block of each packet is recorded, and redundant blocks are used
+// to recover the packets lost before it. Without WithRED, RED tracks are not recorded.
+func WithRED(primaryPT uint8) RecordOption {
+	return func(o *recordOptions) {
+		o.redEnabled = true
+		o.redPrimaryPT = primaryPT
+	}
+}
+
+type redBlock struct {
+	payloadType     uint8
+	timestampOffset uint32
+	payload         []byte
+}
+
+// parseRED splits a RED payload into its blocks, the primary block is the last one
+func parseRED(payload []byte) ([]redBlock, error) {
+	var blocks []redBlock
+	offset := 0
+	for {
+		if offset >= len(payload) {
+			return nil, errMalformedRED
+		}
+		if payload[offset]&0x80 == 0 {
+			// last header, of the primary block
+			blocks = append(blocks, redBlock{payloadType: payload[offset] & 0x7f})
+			offset++
+			break
+		}
+		if offset+4 > len(payload) {
+			return nil, errMalformedRED
+		}
+		header := binary.BigEndian.Uint32(payload[offset:])
+		blocks = append(blocks, redBlock{
+			payloadType:     uint8(header>>24) & 0x7f,
+			timestampOffset: (header >> 10) & 0x3fff,
+			payload:         make([]byte, header&0x3ff),
+		})
+		offset += 4
+	}
+
+	for j := range blocks[:len(blocks)-1] {
+		size := len(blocks[j].payload)
+		if offset+size > len(payload) {
+			return nil, errMalformedRED
+		}
+		blocks[j].payload = payload[offset : offset+size]
+		offset += size
+	}
+	blocks[len(blocks)-1].payload = payload[offset:]
+	return blocks, nil
+}
+
+// redDecoder converts the packets of a RED track to packets of its primary codec
+type redDecoder struct {
+	primaryPT uint8
+
+	started bool
+	lastSeq uint16
+}
+
+// decode returns the primary packet of a RED packet, preceded by the packets lost since the
+// previous one which can be recovered from its redundant blocks. Each block is assumed to hold
+// the payload of a packet, so that the block at distance n from the primary one is the payload
+// of the packet n sequence numbers before. Malformed packets are dropped.
+func (d *redDecoder) decode(packet *rtp.Packet) []*rtp.Packet {
+	blocks, err := parseRED(packet.Payload)
+	if err != nil {
+		return nil
+	}
+
+	var lost uint16
+	if d.started {
+		if diff := packet.SequenceNumber - d.lastSeq; diff < 1<<15 {
+			lost = diff - 1
+			d.lastSeq = packet.SequenceNumber
+		}
+		// older packets arrive too late to recover anything, only their primary block is used
+	} else {
+		d.started = true
+		d.lastSeq = packet.SequenceNumber
+	}
+
+	var packets []*rtp.Packet
+	for j, block := range blocks {
+		distance := uint16(len(blocks) - 1 - j)
+		if block.payloadType != d.primaryPT || distance > lost || len(block.payload) == 0 && distance > 0 {
+			continue
+		}
+		header := packet.Header
+		header.PayloadType = d.primaryPT
+		header.SequenceNumber -= distance
+		header.Timestamp -= block.timestampOffset
+		packets = append(packets, &rtp.Packet{Header: header, Payload: block.payload})
+	}
+	return packets
+}
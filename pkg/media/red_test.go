@@ -0,0 +1,92 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+// redPayload builds a RED payload with the primary block last, redundant blocks are 960 ticks apart
+func redPayload(pt uint8, redundant [][]byte, primary []byte) []byte {
+	var payload []byte
+	for j, block := range redundant {
+		offset := uint32(len(redundant)-j) * 960
+		header := make([]byte, 4)
+		binary.BigEndian.PutUint32(header, 1<<31|uint32(pt)<<24|offset<<10|uint32(len(block)))
+		payload = append(payload, header...)
+	}
+	payload = append(payload, pt)
+	for _, block := range redundant {
+		payload = append(payload, block...)
+	}
+	return append(payload, primary...)
+}
+
+func TestParseRED(t *testing.T) {
+	blocks, err := parseRED(redPayload(111, [][]byte{{1}, {2, 2}}, []byte{3, 3, 3}))
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	require.Equal(t, redBlock{payloadType: 111, timestampOffset: 1920, payload: []byte{1}}, blocks[0])
+	require.Equal(t, redBlock{payloadType: 111, timestampOffset: 960, payload: []byte{2, 2}}, blocks[1])
+	require.Equal(t, redBlock{payloadType: 111, payload: []byte{3, 3, 3}}, blocks[2])
+
+	_, err = parseRED(nil)
+	require.ErrorIs(t, err, errMalformedRED)
+	_, err = parseRED([]byte{0x80 | 111, 0, 0})
+	require.ErrorIs(t, err, errMalformedRED, "truncated header")
+	payload := redPayload(111, [][]byte{{1, 1}}, nil)
+	_, err = parseRED(payload[:len(payload)-1])
+	require.ErrorIs(t, err, errMalformedRED, "truncated block")
+}
+
+func TestREDDecoder(t *testing.T) {
+	d := &redDecoder{primaryPT: 111}
+	packet := func(seq uint16, payload []byte) *rtp.Packet {
+		return &rtp.Packet{
+			Header:  rtp.Header{PayloadType: 63, SequenceNumber: seq, Timestamp: uint32(seq) * 960},
+			Payload: payload,
+		}
+	}
+
+	packets := d.decode(packet(1, redPayload(111, [][]byte{{0}}, []byte{1})))
+	require.Len(t, packets, 1)
+	require.Equal(t, uint8(111), packets[0].PayloadType)
+	require.Equal(t, []byte{1}, packets[0].Payload)
+
+	// packets 2 and 3 lost, 3 is recovered from the redundant block of 4
+	packets = d.decode(packet(4, redPayload(111, [][]byte{{3}}, []byte{4})))
+	require.Len(t, packets, 2)
+	require.Equal(t, uint16(3), packets[0].SequenceNumber)
+	require.Equal(t, uint32(3*960), packets[0].Timestamp)
+	require.Equal(t, []byte{3}, packets[0].Payload)
+	require.Equal(t, uint16(4), packets[1].SequenceNumber)
+
+	// late packets and blocks of other payload types are not recovered
+	packets = d.decode(packet(2, redPayload(111, [][]byte{{1}}, []byte{2})))
+	require.Len(t, packets, 1)
+	packets = d.decode(packet(6, redPayload(100, [][]byte{{5}}, []byte{6})))
+	require.Empty(t, packets)
+
+	require.Empty(t, d.decode(packet(7, []byte{0x80})))
+}
+
+func TestRecordingSession_RED(t *testing.T) {
+	readRTP := func() (*rtp.Packet, error) { return nil, io.EOF }
+
+	s := newRecordingSession(t.TempDir(), recordOptions{manifestName: defaultManifestName, indexName: defaultIndexName})
+	s.addTrack(TrackRecording{TrackSID: "TR_red", ParticipantIdentity: "user", MimeType: "audio/red"}, nil, 48000, 2, readRTP, func() {}, nil)
+	require.NoError(t, s.close())
+	require.Len(t, s.manifest.Tracks, 1)
+	require.Contains(t, s.manifest.Tracks[0].Error, "WithRED")
+
+	s = newRecordingSession(t.TempDir(), recordOptions{manifestName: defaultManifestName, indexName: defaultIndexName})
+	WithRED(111)(&s.opts)
+	s.addTrack(TrackRecording{TrackSID: "TR_red", ParticipantIdentity: "user", MimeType: "audio/red"}, nil, 48000, 2, readRTP, func() {}, nil)
+	require.NoError(t, s.close())
+	require.Len(t, s.manifest.Tracks, 1)
+	require.Empty(t, s.manifest.Tracks[0].Error)
+	require.Equal(t, "user-TR_red.ogg", s.manifest.Tracks[0].File)
+}
@@ -0,0 +1,120 @@
+package media
+
+import (
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go"
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+	"github.com/livekit/server-sdk-go/pkg/samplebuilder"
+)
+
+var _ FrameWriter = (*ivfwriter.IVFWriter)(nil)
+
+// FrameWriter is implemented by writers that take complete frames, such as ivfwriter.IVFWriter
+type FrameWriter interface {
+	WriteFrame(data []byte, keyframe bool, pts uint64) error
+}
+
+// SampleSink writes the samples of a SampleBuilder to a FrameWriter, see SampleBuilderSink
+type SampleSink struct {
+	sb     *samplebuilder.SampleBuilder
+	writer FrameWriter
+	fourcc string
+
+	started     bool
+	pts         uint64
+	minDuration time.Duration
+}
+
+// A SampleSinkOption configures a SampleSink.
+type SampleSinkOption func(s *SampleSink)
+
+// WithSinkCodec sets the codec of the samples, used to detect key frames. The mime type is
+// matched with lksdk.NormalizeCodecMime. Key frames can't be detected for codecs other than VP8
+// and AV1, including unknown ones, whose frames are all written as key frames without error, so
+// that writers never drop them waiting for one. The codec is VP8 by default.
+func WithSinkCodec(mimeType string) SampleSinkOption {
+	return func(s *SampleSink) {
+		switch lksdk.NormalizeCodecMime(mimeType) {
+		case webrtc.MimeTypeVP8:
+			s.fourcc = "VP80"
+		case webrtc.MimeTypeAV1:
+			s.fourcc = "AV01"
+		default:
+			s.fourcc = ""
+		}
+	}
+}
+
+// SampleBuilderSink creates a SampleSink writing the samples of sb to writer. Packets are pushed
+// to sb with WriteRTP, which then writes the samples completed, so that a SampleSink can be used
+// as a RTPWriter.
+//
+// PTS are expressed in frames: the first sample has PTS 0, and the PTS of the next ones advance
+// by their duration divided by the shortest sample duration seen, rounded, and by at least 1.
+// Lost frames thus leave gaps in PTS, as frames reported with IVFWriter.FrameDropped do.
+func SampleBuilderSink(sb *samplebuilder.SampleBuilder, writer FrameWriter, opts ...SampleSinkOption) *SampleSink {
+	s := &SampleSink{
+		sb:     sb,
+		writer: writer,
+		fourcc: "VP80",
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// WriteRTP pushes the packet to the SampleBuilder, and writes the samples completed
+func (s *SampleSink) WriteRTP(packet *rtp.Packet) error {
+	s.sb.Push(packet)
+	return s.Drain()
+}
+
+// Drain writes the samples completed
+func (s *SampleSink) Drain() error {
+	for sample := s.sb.Pop(); sample != nil; sample = s.sb.Pop() {
+		if err := s.writeSample(sample); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes all the samples left in the SampleBuilder, even if they are blocked by missing
+// packets. It is meant to be called when the stream ends.
+func (s *SampleSink) Flush() error {
+	for {
+		sample, _ := s.sb.ForcePopWithTimestamp()
+		if sample == nil {
+			return nil
+		}
+		if err := s.writeSample(sample); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *SampleSink) writeSample(sample *media.Sample) error {
+	if !s.started {
+		s.started = true
+	} else {
+		if sample.Duration > 0 && (s.minDuration == 0 || sample.Duration < s.minDuration) {
+			s.minDuration = sample.Duration
+		}
+		frames := uint64(1)
+		if s.minDuration > 0 {
+			if n := uint64((sample.Duration + s.minDuration/2) / s.minDuration); n > 1 {
+				frames = n
+			}
+		}
+		s.pts += frames
+	}
+
+	keyframe := s.fourcc == "" || isIVFKeyFrame(s.fourcc, sample.Data)
+	return s.writer.WriteFrame(sample.Data, keyframe, s.pts)
+}
@@ -0,0 +1,52 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/samplebuilder"
+)
+
+type frameRecorder struct {
+	keyframes []bool
+	pts       []uint64
+}
+
+func (r *frameRecorder) WriteFrame(_ []byte, keyframe bool, pts uint64) error {
+	r.keyframes = append(r.keyframes, keyframe)
+	r.pts = append(r.pts, pts)
+	return nil
+}
+
+func TestSampleBuilderSink(t *testing.T) {
+	rec := &frameRecorder{}
+	sink := SampleBuilderSink(samplebuilder.New(10, &codecs.VP8Packet{}, 90000), rec)
+
+	for seq, ts := range []uint32{0, 3000, 6000, 12000, 15000} {
+		payload := []byte{0x10, 0x01, 0xff, 0xff}
+		if seq == 0 {
+			payload[1] = 0x00
+		}
+		require.NoError(t, sink.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(seq), Timestamp: ts, Marker: true},
+			Payload: payload,
+		}))
+	}
+	require.NoError(t, sink.Flush())
+
+	require.Equal(t, []uint64{0, 1, 2, 4, 5}, rec.pts, "the gap of a frame is kept")
+	require.Equal(t, []bool{true, false, false, false, false}, rec.keyframes)
+
+	rec = &frameRecorder{}
+	sink = SampleBuilderSink(samplebuilder.New(10, &codecs.VP8Packet{}, 90000), rec, WithSinkCodec("video/H264"))
+	require.NoError(t, sink.WriteRTP(&rtp.Packet{Header: rtp.Header{Marker: true}, Payload: []byte{0x10, 0x01, 0xff, 0xff}}))
+	require.NoError(t, sink.Flush())
+	require.Equal(t, []bool{true}, rec.keyframes, "frames of other codecs are key frames")
+
+	require.Equal(t, "VP80", SampleBuilderSink(nil, rec, WithSinkCodec("VP8")).fourcc)
+	require.Equal(t, "AV01", SampleBuilderSink(nil, rec, WithSinkCodec("video/av1;profile=0")).fourcc)
+	require.Empty(t, SampleBuilderSink(nil, rec, WithSinkCodec("unknown")).fourcc)
+}
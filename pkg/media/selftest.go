@@ -0,0 +1,127 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+const (
+	selfTestFrames     = 30
+	selfTestFrameSize  = 2000
+	selfTestPacketSize = 1200
+	selfTestKeyFrames  = 10
+)
+
+// SelfTest writes a synthetic VP8 stream with IVFWriter and reads it back, to verify that
+// recordings can be written in the current environment. It returns nil on success.
+func SelfTest() error {
+	out := &seekBuffer{}
+	writer, err := ivfwriter.NewWith(out)
+	if err != nil {
+		return err
+	}
+
+	var sequenceNumber uint16
+	for idx := 0; idx < selfTestFrames; idx++ {
+		frame := selfTestFrame(idx)
+		for offset := 0; offset < len(frame); offset += selfTestPacketSize {
+			end := offset + selfTestPacketSize
+			if end > len(frame) {
+				end = len(frame)
+			}
+			// VP8 payload descriptor, with the start of partition bit on the first packet
+			descriptor := byte(0x00)
+			if offset == 0 {
+				descriptor = 0x10
+			}
+			packet := &rtp.Packet{
+				Header: rtp.Header{
+					SequenceNumber: sequenceNumber,
+					Timestamp:      uint32(idx) * 3000,
+					Marker:         end == len(frame),
+				},
+				Payload: append([]byte{descriptor}, frame[offset:end]...),
+			}
+			sequenceNumber++
+			if err = writer.WriteRTP(packet); err != nil {
+				return fmt.Errorf("self test: writing packet: %w", err)
+			}
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("self test: closing writer: %w", err)
+	}
+
+	reader, header, err := ivfreader.NewWith(bytes.NewReader(out.buf))
+	if err != nil {
+		return fmt.Errorf("self test: reading header: %w", err)
+	}
+	if header.FourCC != "VP80" || header.NumFrames != selfTestFrames {
+		return fmt.Errorf("self test: unexpected header %s with %d frames", header.FourCC, header.NumFrames)
+	}
+
+	for idx := 0; ; idx++ {
+		frame, frameHeader, err := reader.ParseNextFrame()
+		if errors.Is(err, io.EOF) {
+			if idx != selfTestFrames {
+				return fmt.Errorf("self test: read %d frames, expected %d", idx, selfTestFrames)
+			}
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("self test: reading frame %d: %w", idx, err)
+		}
+		if frameHeader.Timestamp != uint64(idx) || !bytes.Equal(frame, selfTestFrame(idx)) {
+			return fmt.Errorf("self test: frame %d does not match", idx)
+		}
+	}
+}
+
+// selfTestFrame returns a frame with a VP8 frame tag, and the start code and dimensions on key frames
+func selfTestFrame(idx int) []byte {
+	frame := make([]byte, selfTestFrameSize)
+	for j := range frame {
+		frame[j] = byte(idx + j)
+	}
+	if idx%selfTestKeyFrames == 0 {
+		copy(frame, []byte{0x00, 0x00, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01})
+	} else {
+		frame[0] = 0x01
+	}
+	return frame
+}
+
+// seekBuffer is an in memory io.WriteSeeker
+type seekBuffer struct {
+	buf    []byte
+	offset int
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if end := b.offset + len(p); end > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, end-len(b.buf))...)
+	}
+	n := copy(b.buf[b.offset:], p)
+	b.offset += n
+	return n, nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(b.offset)
+	case io.SeekEnd:
+		offset += int64(len(b.buf))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	b.offset = int(offset)
+	return offset, nil
+}
@@ -0,0 +1,50 @@
+package media
+
+import (
+	"github.com/pion/rtp"
+)
+
+// SequenceRewriter forwards RTP packets to a RTPWriter, with contiguous sequence numbers.
+// Packets are numbered in the order they are written, from a base sequence number, so gaps
+// left by packets dropped while recording are removed. RTP timestamps are not changed.
+type SequenceRewriter struct {
+	writer RTPWriter
+	next   uint16
+
+	rewriteSSRC bool
+	ssrc        uint32
+}
+
+// A SequenceRewriterOption configures a SequenceRewriter.
+type SequenceRewriterOption func(r *SequenceRewriter)
+
+// WithRewrittenSSRC also replaces the SSRC of every packet with ssrc
+func WithRewrittenSSRC(ssrc uint32) SequenceRewriterOption {
+	return func(r *SequenceRewriter) {
+		r.rewriteSSRC = true
+		r.ssrc = ssrc
+	}
+}
+
+// NewSequenceRewriter creates a SequenceRewriter writing to writer, base is the sequence number of the first packet
+func NewSequenceRewriter(writer RTPWriter, base uint16, opts ...SequenceRewriterOption) *SequenceRewriter {
+	r := &SequenceRewriter{
+		writer: writer,
+		next:   base,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// WriteRTP forwards a copy of the packet with the next sequence number, the packet itself is not modified
+func (r *SequenceRewriter) WriteRTP(packet *rtp.Packet) error {
+	rewritten := *packet
+	rewritten.SequenceNumber = r.next
+	if r.rewriteSSRC {
+		rewritten.SSRC = r.ssrc
+	}
+	r.next++
+	return r.writer.WriteRTP(&rewritten)
+}
@@ -0,0 +1,34 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceRewriter(t *testing.T) {
+	rec := &packetRecorder{}
+	r := NewSequenceRewriter(rec, 65534, WithRewrittenSSRC(42))
+
+	var input []*rtp.Packet
+	for _, seq := range []uint16{10, 11, 15, 20} {
+		packet := &rtp.Packet{Header: rtp.Header{SequenceNumber: seq, Timestamp: uint32(seq) * 3000, SSRC: 1}}
+		input = append(input, packet)
+		require.NoError(t, r.WriteRTP(packet))
+	}
+
+	require.Len(t, rec.packets, 4)
+	for i, seq := range []uint16{65534, 65535, 0, 1} {
+		require.Equal(t, seq, rec.packets[i].SequenceNumber)
+		require.Equal(t, uint32(42), rec.packets[i].SSRC)
+		require.Equal(t, input[i].Timestamp, rec.packets[i].Timestamp)
+	}
+	require.Equal(t, uint16(15), input[2].SequenceNumber, "input packets are not modified")
+
+	rec = &packetRecorder{}
+	r = NewSequenceRewriter(rec, 0)
+	require.NoError(t, r.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 7, SSRC: 1}}))
+	require.Equal(t, uint32(1), rec.packets[0].SSRC)
+	require.Equal(t, uint16(0), rec.packets[0].SequenceNumber)
+}
@@ -0,0 +1,78 @@
+package media
+
+import (
+	"time"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+// SessionStats aggregates the statistics of the tracks recorded by a RecordingSession
+type SessionStats struct {
+	Room   string
+	Tracks []TrackStats
+	// Frames and Bytes are the totals of the tracks, Bitrate the sum of their bitrates
+	Frames  uint64
+	Bytes   uint64
+	Bitrate float64
+}
+
+// TrackStats are the statistics of a recorded track. Frames are the frames written for IVF files,
+// and the Opus packets for Ogg files. Bytes count the frames, without container overhead, and
+// Bitrate, in bits per second, is measured over Duration, from the RTP timestamps.
+type TrackStats struct {
+	TrackSID            string
+	ParticipantIdentity string
+	MimeType            string
+	File                string
+	Frames              uint64
+	Bytes               uint64
+	Duration            time.Duration
+	Bitrate             float64
+	// Done is set once the writer of the track is closed
+	Done bool
+}
+
+// Stats returns the statistics of the tracks being recorded, or recorded once the session is stopped
+func (s *RecordingSession) Stats() SessionStats {
+	s.lock.Lock()
+	stats := SessionStats{Room: s.manifest.Room}
+	recorders := s.recorders
+	s.lock.Unlock()
+
+	for _, r := range recorders {
+		track := r.stats()
+		stats.Tracks = append(stats.Tracks, track)
+		stats.Frames += track.Frames
+		stats.Bytes += track.Bytes
+		stats.Bitrate += track.Bitrate
+	}
+	return stats
+}
+
+func (r *trackRecorder) stats() TrackStats {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats := TrackStats{
+		TrackSID:            r.entry.TrackSID,
+		ParticipantIdentity: r.entry.ParticipantIdentity,
+		MimeType:            r.entry.MimeType,
+		File:                r.entry.File,
+	}
+	if r.ivf != nil {
+		ivfStats := r.ivf.Stats()
+		stats.Frames, stats.Bytes, stats.Duration = ivfStats.FrameCount, ivfStats.Bytes, ivfStats.Duration
+	} else if !r.startedAt.IsZero() {
+		stats.Frames, stats.Bytes = r.packets, r.bytes
+		stats.Duration = ivfwriter.RTPDuration(r.firstTimestamp, r.lastTimestamp, r.clockRate, r.timestampWraps)
+	}
+	if stats.Duration > 0 {
+		stats.Bitrate = float64(stats.Bytes) * 8 / stats.Duration.Seconds()
+	}
+	select {
+	case <-r.done:
+		stats.Done = true
+	default:
+	}
+	return stats
+}
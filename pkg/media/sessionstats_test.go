@@ -0,0 +1,54 @@
+package media
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingSession_Stats(t *testing.T) {
+	s := newRecordingSession(t.TempDir(), recordOptions{manifestName: defaultManifestName, indexName: defaultIndexName})
+	s.setRoom("RM_1", "room")
+	require.Empty(t, s.Stats().Tracks)
+
+	packets := make(chan *rtp.Packet, 8)
+	readRTP := func() (*rtp.Packet, error) {
+		if packet, ok := <-packets; ok {
+			return packet, nil
+		}
+		return nil, io.EOF
+	}
+	s.addTrack(TrackRecording{TrackSID: "TR_video", ParticipantIdentity: "user", MimeType: "video/VP8"},
+		nil, 90000, 0, readRTP, func() {}, nil)
+
+	for j := 0; j < 4; j++ {
+		packet := vp8Packet(uint32(j)*9000, j == 0, byte(j))
+		packet.SequenceNumber = uint16(j + 1)
+		packets <- packet
+	}
+	require.Eventually(t, func() bool {
+		return s.Stats().Frames == 4
+	}, time.Second, time.Millisecond, "frames are counted as they are written")
+
+	stats := s.Stats()
+	require.Equal(t, "room", stats.Room)
+	require.Len(t, stats.Tracks, 1)
+	track := stats.Tracks[0]
+	require.Equal(t, "TR_video", track.TrackSID)
+	require.Equal(t, "video/VP8", track.MimeType)
+	require.False(t, track.Done)
+	require.Equal(t, uint64(12), track.Bytes)
+	require.Equal(t, 300*time.Millisecond, track.Duration)
+	require.InDelta(t, 320, track.Bitrate, 1e-9)
+	require.Equal(t, track.Bitrate, stats.Bitrate)
+
+	close(packets)
+	require.NoError(t, s.Stop())
+	stats = s.Stats()
+	require.True(t, stats.Tracks[0].Done)
+	require.Equal(t, uint64(4), stats.Frames)
+	require.Equal(t, uint64(12), stats.Bytes)
+}
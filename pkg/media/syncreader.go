@@ -0,0 +1,139 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
+	"github.com/pion/webrtc/v3/pkg/media/oggreader"
+)
+
+// opus granule positions are always expressed at 48kHz
+const opusGranuleRate = 48000
+
+var opusTagsSignature = []byte("OpusTags")
+
+type SampleKind string
+
+const (
+	SampleKindVideo SampleKind = "video"
+	SampleKindAudio SampleKind = "audio"
+)
+
+// SyncSample is a video frame or an audio page, PTS is relative to the start of the session
+type SyncSample struct {
+	Kind SampleKind
+	PTS  time.Duration
+	Data []byte
+}
+
+// SyncReader reads an IVF and an OGG recording of the same session, and returns their
+// samples interleaved in PTS order. Start offsets are the times each recording started
+// at relative to the session, typically taken from the recording metadata.
+type SyncReader struct {
+	video       *ivfreader.IVFReader
+	videoHeader *ivfreader.IVFFileHeader
+	videoStart  time.Duration
+	nextVideo   *SyncSample
+
+	audio       *oggreader.OggReader
+	audioHeader *oggreader.OggHeader
+	audioStart  time.Duration
+	lastGranule uint64
+	nextAudio   *SyncSample
+}
+
+// NewSyncReader creates a SyncReader, video or audio can be nil to read a single recording
+func NewSyncReader(
+	video *ivfreader.IVFReader, videoHeader *ivfreader.IVFFileHeader, videoStart time.Duration,
+	audio *oggreader.OggReader, audioHeader *oggreader.OggHeader, audioStart time.Duration,
+) *SyncReader {
+	return &SyncReader{
+		video:       video,
+		videoHeader: videoHeader,
+		videoStart:  videoStart,
+		audio:       audio,
+		audioHeader: audioHeader,
+		audioStart:  audioStart,
+	}
+}
+
+// Next returns the sample with the lowest PTS, or io.EOF when both recordings were fully read
+func (s *SyncReader) Next() (*SyncSample, error) {
+	if err := s.fill(); err != nil {
+		return nil, err
+	}
+
+	var sample *SyncSample
+	switch {
+	case s.nextVideo == nil && s.nextAudio == nil:
+		return nil, io.EOF
+	case s.nextAudio == nil, s.nextVideo != nil && s.nextVideo.PTS <= s.nextAudio.PTS:
+		sample, s.nextVideo = s.nextVideo, nil
+	default:
+		sample, s.nextAudio = s.nextAudio, nil
+	}
+	return sample, nil
+}
+
+func (s *SyncReader) fill() error {
+	if s.nextVideo == nil && s.video != nil {
+		frame, header, err := s.video.ParseNextFrame()
+		switch {
+		case errors.Is(err, io.EOF):
+			s.video = nil
+		case err != nil:
+			return err
+		default:
+			s.nextVideo = &SyncSample{
+				Kind: SampleKindVideo,
+				PTS:  s.videoStart + s.videoPTS(header.Timestamp),
+				Data: frame,
+			}
+		}
+	}
+
+	for s.nextAudio == nil && s.audio != nil {
+		page, header, err := s.audio.ParseNextPage()
+		switch {
+		case errors.Is(err, io.EOF):
+			s.audio = nil
+		case err != nil:
+			return err
+		case bytes.HasPrefix(page, opusTagsSignature):
+			// the comment header follows the ID header read by oggreader.NewWith
+			continue
+		default:
+			// a page's granule position is the end of its last sample, so the page
+			// starts where the previous one ended
+			s.nextAudio = &SyncSample{
+				Kind: SampleKindAudio,
+				PTS:  s.audioStart + s.audioPTS(s.lastGranule),
+				Data: page,
+			}
+			s.lastGranule = header.GranulePosition
+		}
+	}
+
+	return nil
+}
+
+func (s *SyncReader) videoPTS(timestamp uint64) time.Duration {
+	if s.videoHeader.TimebaseDenominator == 0 {
+		return 0
+	}
+	return time.Duration(timestamp) * time.Second * time.Duration(s.videoHeader.TimebaseNumerator) / time.Duration(s.videoHeader.TimebaseDenominator)
+}
+
+func (s *SyncReader) audioPTS(granule uint64) time.Duration {
+	preSkip := uint64(0)
+	if s.audioHeader != nil {
+		preSkip = uint64(s.audioHeader.PreSkip)
+	}
+	if granule < preSkip {
+		return 0
+	}
+	return time.Duration(granule-preSkip) * time.Second / opusGranuleRate
+}
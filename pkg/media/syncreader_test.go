@@ -0,0 +1,109 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
+	"github.com/pion/webrtc/v3/pkg/media/oggreader"
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+	"github.com/stretchr/testify/require"
+)
+
+// buildIVF returns a VP8 IVF file at 1/30 timebase with one byte frames at the given pts
+func buildIVF(pts ...uint64) []byte {
+	header := make([]byte, 32)
+	copy(header[0:], "DKIF")
+	binary.LittleEndian.PutUint16(header[6:], 32)
+	copy(header[8:], "VP80")
+	binary.LittleEndian.PutUint32(header[16:], 30)
+	binary.LittleEndian.PutUint32(header[20:], 1)
+	binary.LittleEndian.PutUint32(header[24:], uint32(len(pts)))
+
+	buf := bytes.NewBuffer(header)
+	for idx, p := range pts {
+		frame := make([]byte, 12)
+		binary.LittleEndian.PutUint32(frame[0:], 1)
+		binary.LittleEndian.PutUint64(frame[4:], p)
+		buf.Write(frame)
+		buf.WriteByte(byte(idx))
+	}
+	return buf.Bytes()
+}
+
+// buildOGG returns an opus OGG file with count 20ms pages
+func buildOGG(t *testing.T, count int) []byte {
+	buf := &bytes.Buffer{}
+	w, err := oggwriter.NewWith(buf, 48000, 2)
+	require.NoError(t, err)
+	for idx := 0; idx < count; idx++ {
+		require.NoError(t, w.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(idx), Timestamp: uint32(idx * 960)},
+			Payload: []byte{byte(idx)},
+		}))
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, r *SyncReader) []*SyncSample {
+	var samples []*SyncSample
+	for {
+		sample, err := r.Next()
+		if err == io.EOF {
+			return samples
+		}
+		require.NoError(t, err)
+		samples = append(samples, sample)
+	}
+}
+
+func TestSyncReader(t *testing.T) {
+	t.Run("interleaves by pts", func(t *testing.T) {
+		video, videoHeader, err := ivfreader.NewWith(bytes.NewReader(buildIVF(0, 1, 2)))
+		require.NoError(t, err)
+		audio, audioHeader, err := oggreader.NewWith(bytes.NewReader(buildOGG(t, 4)))
+		require.NoError(t, err)
+
+		// video starts 10ms after audio
+		r := NewSyncReader(video, videoHeader, 10*time.Millisecond, audio, audioHeader, 0)
+		samples := readAll(t, r)
+		require.Len(t, samples, 7)
+		require.Equal(t, []byte{0}, samples[0].Data)
+
+		for idx := 1; idx < len(samples); idx++ {
+			require.LessOrEqual(t, samples[idx-1].PTS, samples[idx].PTS)
+		}
+
+		var videoPTS []time.Duration
+		for _, s := range samples {
+			if s.Kind == SampleKindVideo {
+				videoPTS = append(videoPTS, s.PTS)
+			}
+		}
+		require.Equal(t, []time.Duration{
+			10 * time.Millisecond,
+			10*time.Millisecond + time.Second/30,
+			10*time.Millisecond + 2*time.Second/30,
+		}, videoPTS)
+		require.Equal(t, SampleKindAudio, samples[0].Kind)
+	})
+
+	t.Run("single source", func(t *testing.T) {
+		video, videoHeader, err := ivfreader.NewWith(bytes.NewReader(buildIVF(0, 3)))
+		require.NoError(t, err)
+
+		samples := readAll(t, NewSyncReader(video, videoHeader, 0, nil, nil, 0))
+		require.Len(t, samples, 2)
+		require.Equal(t, []byte{1}, samples[1].Data)
+		require.Equal(t, 100*time.Millisecond, samples[1].PTS)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewSyncReader(nil, nil, 0, nil, nil, 0).Next()
+		require.ErrorIs(t, err, io.EOF)
+	})
+}
@@ -0,0 +1,68 @@
+package media
+
+import (
+	"time"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+const (
+	// TrackIndexVersion is the version of the schema of TrackIndex. Fields may be added
+	// without changing it, it changes when fields are removed or their meaning changes.
+	TrackIndexVersion = 1
+
+	defaultIndexName = "index.json"
+)
+
+// TrackIndex links the files written by RecordRoom to the tracks they were recorded from
+type TrackIndex struct {
+	Version int               `json:"version"`
+	Tracks  []TrackIndexEntry `json:"tracks"`
+}
+
+// TrackIndexEntry describes a file of a TrackIndex. Width and Height are parsed from VP8 key
+// frames, or come from the TrackInfo of the track, and are 0 for audio tracks. StartedAt is the
+// wall clock time the first packet was written, and Duration is measured from RTP timestamps.
+type TrackIndexEntry struct {
+	File                string        `json:"file"`
+	RoomSID             string        `json:"room_sid"`
+	ParticipantSID      string        `json:"participant_sid"`
+	ParticipantIdentity string        `json:"participant_identity"`
+	TrackSID            string        `json:"track_sid"`
+	TrackName           string        `json:"track_name"`
+	Codec               string        `json:"codec"`
+	Width               uint32        `json:"width"`
+	Height              uint32        `json:"height"`
+	StartedAt           time.Time     `json:"started_at"`
+	Duration            time.Duration `json:"duration"`
+}
+
+// WithIndexName changes the name of the TrackIndex written in the output directory, index.json by default
+func WithIndexName(name string) RecordOption {
+	return func(o *recordOptions) {
+		o.indexName = name
+	}
+}
+
+func (r *trackRecorder) indexEntry(roomSID string) TrackIndexEntry {
+	entry := TrackIndexEntry{
+		File:                r.entry.File,
+		RoomSID:             roomSID,
+		ParticipantSID:      r.entry.ParticipantSID,
+		ParticipantIdentity: r.entry.ParticipantIdentity,
+		TrackSID:            r.entry.TrackSID,
+		TrackName:           r.entry.Name,
+		Codec:               r.entry.MimeType,
+		Width:               uint32(r.result.Width),
+		Height:              uint32(r.result.Height),
+		StartedAt:           r.startedAt,
+		Duration:            r.entry.Duration,
+	}
+	if entry.Width == 0 || entry.Height == 0 {
+		entry.Width, entry.Height = r.info.GetWidth(), r.info.GetHeight()
+	}
+	if r.ivf == nil && !r.startedAt.IsZero() {
+		entry.Duration = ivfwriter.RTPDuration(r.firstTimestamp, r.lastTimestamp, r.clockRate, r.timestampWraps)
+	}
+	return entry
+}
@@ -0,0 +1,73 @@
+package media
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackIndex(t *testing.T) {
+	outDir := t.TempDir()
+	s := newRecordingSession(outDir, recordOptions{manifestName: defaultManifestName, indexName: "catalog.json"})
+	s.setRoom("RM_1", "room")
+
+	packets := func(payloads ...[]byte) func() (*rtp.Packet, error) {
+		n := 0
+		return func() (*rtp.Packet, error) {
+			if n == len(payloads) {
+				return nil, io.EOF
+			}
+			n++
+			return &rtp.Packet{
+				Header:  rtp.Header{SequenceNumber: uint16(n), Timestamp: uint32(n) * 960, Marker: true},
+				Payload: payloads[n-1],
+			}, nil
+		}
+	}
+
+	before := time.Now()
+	s.addTrack(TrackRecording{
+		TrackSID:            "TR_audio",
+		ParticipantSID:      "PA_1",
+		ParticipantIdentity: "user",
+		Name:                "microphone",
+		Kind:                "audio",
+		MimeType:            "audio/opus",
+	}, &livekit.TrackInfo{Sid: "TR_audio"}, 48000, 2, packets([]byte{0x78}, []byte{0x78}, []byte{0x78}), func() {}, nil)
+	s.addTrack(TrackRecording{
+		TrackSID: "TR_video",
+		Kind:     "video",
+		MimeType: "video/VP8",
+	}, &livekit.TrackInfo{Sid: "TR_video", Type: livekit.TrackType_VIDEO, Width: 1280, Height: 720}, 90000, 0, packets([]byte{0x10, 0x00, 0xff, 0xff}), func() {}, nil)
+	s.addTrack(TrackRecording{TrackSID: "TR_h264", MimeType: "video/H264"}, nil, 90000, 0, packets(), func() {}, nil)
+	require.NoError(t, s.close())
+
+	data, err := ioutil.ReadFile(filepath.Join(outDir, "catalog.json"))
+	require.NoError(t, err)
+	var index TrackIndex
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Equal(t, TrackIndexVersion, index.Version)
+	require.Len(t, index.Tracks, 2, "tracks not recorded are not indexed")
+
+	audio := index.Tracks[0]
+	require.Equal(t, "user-TR_audio.ogg", audio.File)
+	require.Equal(t, "RM_1", audio.RoomSID)
+	require.Equal(t, "PA_1", audio.ParticipantSID)
+	require.Equal(t, "microphone", audio.TrackName)
+	require.Equal(t, "audio/opus", audio.Codec)
+	require.Zero(t, audio.Width)
+	require.Equal(t, 40*time.Millisecond, audio.Duration)
+	require.False(t, audio.StartedAt.Before(before.Truncate(time.Second)))
+
+	video := index.Tracks[1]
+	require.Equal(t, "-TR_video.ivf", video.File)
+	require.Equal(t, uint32(1280), video.Width, "dimensions of the track info without VP8 dimensions")
+	require.Equal(t, uint32(720), video.Height)
+}
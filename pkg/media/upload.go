@@ -0,0 +1,180 @@
+package media
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	errUploadClosed       = errors.New("upload sink is closed")
+	errSeekAfterFirstPart = errors.New("upload sink can only seek within the first part")
+	errInvalidPartSize    = errors.New("part size must be positive")
+)
+
+// MultipartUploader uploads an object in parts, as supported by S3 or GCS. Parts are numbered
+// from 1 and can be uploaded in any order, all of them but the last one have the same size.
+type MultipartUploader interface {
+	UploadPart(number int, data []byte) error
+	Complete() error
+	Abort() error
+}
+
+// UploadSink streams a recording to an object store. The first part is kept in memory until
+// Close, so that writers can seek back to update their header, as IVFWriter does on Close.
+// The following parts are uploaded as soon as they are full.
+type UploadSink struct {
+	uploader MultipartUploader
+	partSize int
+
+	first   []byte
+	current []byte
+	// number of the part being filled, starting at 2 after the first part
+	partNumber int
+
+	size   int64
+	offset int64
+	closed bool
+	err    error
+}
+
+// NewUploadSink creates an UploadSink uploading parts of partSize bytes
+func NewUploadSink(uploader MultipartUploader, partSize int) (*UploadSink, error) {
+	if partSize <= 0 {
+		return nil, errInvalidPartSize
+	}
+	return &UploadSink{
+		uploader:   uploader,
+		partSize:   partSize,
+		first:      make([]byte, 0, partSize),
+		partNumber: 2,
+	}, nil
+}
+
+func (u *UploadSink) Write(p []byte) (int, error) {
+	if u.closed {
+		return 0, errUploadClosed
+	} else if u.err != nil {
+		return 0, u.err
+	}
+
+	// overwrite within the first part
+	if u.offset < u.size {
+		if u.offset+int64(len(p)) > int64(len(u.first)) {
+			return 0, errSeekAfterFirstPart
+		}
+		n := copy(u.first[u.offset:], p)
+		u.offset += int64(n)
+		return n, nil
+	}
+
+	written := 0
+	if len(p) > 0 && len(u.first) < u.partSize {
+		n := u.partSize - len(u.first)
+		if n > len(p) {
+			n = len(p)
+		}
+		u.first = append(u.first, p[:n]...)
+		written += n
+		p = p[n:]
+	}
+
+	for len(p) > 0 {
+		n := u.partSize - len(u.current)
+		if n > len(p) {
+			n = len(p)
+		}
+		u.current = append(u.current, p[:n]...)
+		written += n
+		p = p[n:]
+
+		if len(u.current) == u.partSize {
+			if err := u.uploadCurrent(); err != nil {
+				u.size += int64(written)
+				u.offset = u.size
+				return written, err
+			}
+		}
+	}
+
+	u.size += int64(written)
+	u.offset = u.size
+	return written, nil
+}
+
+// Seek moves within the first part, or to the end of the data written
+func (u *UploadSink) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += u.offset
+	case io.SeekEnd:
+		offset += u.size
+	}
+
+	if offset != u.size && (offset < 0 || offset >= int64(len(u.first))) {
+		return u.offset, errSeekAfterFirstPart
+	}
+	u.offset = offset
+	return offset, nil
+}
+
+// Close uploads the first and the last part, and completes the upload. If any upload failed,
+// the upload is aborted. Closing again is a no-op.
+func (u *UploadSink) Close() error {
+	if u.closed {
+		return nil
+	}
+	u.closed = true
+
+	if u.err == nil && len(u.current) > 0 {
+		u.err = u.uploadCurrent()
+	}
+	if u.err == nil {
+		u.err = u.uploader.UploadPart(1, u.first)
+	}
+	if u.err != nil {
+		_ = u.uploader.Abort()
+		return u.err
+	}
+	u.err = u.uploader.Complete()
+	return u.err
+}
+
+func (u *UploadSink) uploadCurrent() error {
+	if err := u.uploader.UploadPart(u.partNumber, u.current); err != nil {
+		u.err = err
+		return err
+	}
+	u.partNumber++
+	u.current = make([]byte, 0, u.partSize)
+	return nil
+}
+
+// WriterAtUploader is a MultipartUploader writing parts at their offset, such as in an os.File
+type WriterAtUploader struct {
+	w        io.WriterAt
+	partSize int
+}
+
+// NewWriterAtUploader creates a WriterAtUploader, partSize must match the one of the UploadSink
+func NewWriterAtUploader(w io.WriterAt, partSize int) *WriterAtUploader {
+	return &WriterAtUploader{
+		w:        w,
+		partSize: partSize,
+	}
+}
+
+func (w *WriterAtUploader) UploadPart(number int, data []byte) error {
+	_, err := w.w.WriteAt(data, int64(number-1)*int64(w.partSize))
+	return err
+}
+
+func (w *WriterAtUploader) Complete() error {
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (w *WriterAtUploader) Abort() error {
+	return w.Complete()
+}
@@ -0,0 +1,131 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/server-sdk-go/pkg/media/ivfwriter"
+)
+
+type testUploader struct {
+	parts     map[int][]byte
+	completed bool
+	aborted   bool
+	err       error
+
+	completeErr error
+}
+
+func (u *testUploader) UploadPart(number int, data []byte) error {
+	if u.err != nil {
+		return u.err
+	}
+	if u.parts == nil {
+		u.parts = make(map[int][]byte)
+	}
+	u.parts[number] = append([]byte{}, data...)
+	return nil
+}
+
+func (u *testUploader) Complete() error {
+	u.completed = true
+	return u.completeErr
+}
+
+func (u *testUploader) Abort() error {
+	u.aborted = true
+	return nil
+}
+
+func TestUploadSink(t *testing.T) {
+	_, err := NewUploadSink(&testUploader{}, 0)
+	require.ErrorIs(t, err, errInvalidPartSize)
+
+	t.Run("parts", func(t *testing.T) {
+		uploader := &testUploader{}
+		sink, err := NewUploadSink(uploader, 4)
+		require.NoError(t, err)
+
+		_, err = sink.Write([]byte("abcdefghij"))
+		require.NoError(t, err)
+		require.Len(t, uploader.parts, 1, "only the second part is full and uploaded")
+
+		_, err = sink.Seek(1, io.SeekStart)
+		require.NoError(t, err)
+		_, err = sink.Write([]byte("BC"))
+		require.NoError(t, err)
+		_, err = sink.Write([]byte("DE"))
+		require.ErrorIs(t, err, errSeekAfterFirstPart)
+		_, err = sink.Seek(5, io.SeekStart)
+		require.ErrorIs(t, err, errSeekAfterFirstPart)
+
+		offset, err := sink.Seek(0, io.SeekEnd)
+		require.NoError(t, err)
+		require.Equal(t, int64(10), offset)
+		_, err = sink.Write([]byte("k"))
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Close())
+		require.NoError(t, sink.Close())
+		require.True(t, uploader.completed)
+		require.Equal(t, map[int][]byte{
+			1: []byte("aBCd"),
+			2: []byte("efgh"),
+			3: []byte("ijk"),
+		}, uploader.parts)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		uploader := &testUploader{err: errors.New("failed")}
+		sink, err := NewUploadSink(uploader, 4)
+		require.NoError(t, err)
+		_, err = sink.Write([]byte("abcdefgh"))
+		require.Error(t, err)
+		require.Error(t, sink.Close())
+		require.True(t, uploader.aborted)
+	})
+
+	t.Run("complete failed", func(t *testing.T) {
+		completeErr := errors.New("failed")
+		uploader := &testUploader{completeErr: completeErr}
+		sink, err := NewUploadSink(uploader, 4)
+		require.NoError(t, err)
+		_, err = sink.Write([]byte("abc"))
+		require.NoError(t, err)
+		require.ErrorIs(t, sink.Close(), completeErr)
+		require.ErrorIs(t, sink.err, completeErr)
+		require.NoError(t, sink.Close())
+		_, err = sink.Write([]byte("d"))
+		require.ErrorIs(t, err, errUploadClosed)
+	})
+
+	t.Run("ivf", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "upload.ivf")
+		f, err := os.Create(name)
+		require.NoError(t, err)
+		sink, err := NewUploadSink(NewWriterAtUploader(f, 64), 64)
+		require.NoError(t, err)
+
+		writer, err := ivfwriter.NewWith(sink)
+		require.NoError(t, err)
+		for idx := 0; idx < 10; idx++ {
+			require.NoError(t, writer.WriteRTP(&rtp.Packet{
+				Header:  rtp.Header{Timestamp: uint32(idx) * 3000, Marker: true},
+				Payload: []byte{0x10, 0x00, 0xff, 0xff},
+			}))
+		}
+		require.NoError(t, writer.Close())
+
+		b, err := os.ReadFile(name)
+		require.NoError(t, err)
+		require.Len(t, b, 32+10*15)
+		// frame count was updated in the header
+		require.Equal(t, byte(10), b[24])
+	})
+}
@@ -0,0 +1,61 @@
+package media
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch, 1900, and the Unix epoch
+const ntpEpochOffset = 2208988800
+
+// WallclockMapper converts the RTP timestamps of a stream to the wall clock time of its sender,
+// from the NTP and RTP timestamps pairs of the RTCP sender reports of the stream. It is safe for
+// concurrent use, so that reports can be observed while packets are read.
+type WallclockMapper struct {
+	clockRate uint32
+
+	lock     sync.RWMutex
+	observed bool
+	ntpTime  time.Time
+	rtpTime  uint32
+}
+
+// NewWallclockMapper builds a WallclockMapper for a stream with the given RTP clock rate
+func NewWallclockMapper(clockRate uint32) *WallclockMapper {
+	return &WallclockMapper{clockRate: clockRate}
+}
+
+// Observe records the mapping of a sender report, replacing the previous one so that the drift
+// between the clocks of the sender is followed. Reports of other streams must be filtered out
+// by the caller, using their SSRC.
+func (m *WallclockMapper) Observe(sr *rtcp.SenderReport) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.observed = true
+	m.ntpTime = ntpToTime(sr.NTPTime)
+	m.rtpTime = sr.RTPTime
+}
+
+// ToWallclock returns the wall clock time of the sender at rtpTS, and false until a sender report
+// was observed or when the clock rate is 0. rtpTS must be within half the range of RTP timestamps
+// of the last report, about 6 hours at 90kHz, since wraps around zero cannot be told apart.
+func (m *WallclockMapper) ToWallclock(rtpTS uint32) (time.Time, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if !m.observed || m.clockRate == 0 {
+		return time.Time{}, false
+	}
+	ticks := int64(int32(rtpTS - m.rtpTime))
+	return m.ntpTime.Add(time.Duration(ticks) * time.Second / time.Duration(m.clockRate)), true
+}
+
+// ntpToTime converts a 64 bits NTP timestamp, seconds and fraction of second in fixed point
+func ntpToTime(ntp uint64) time.Time {
+	seconds := int64(ntp>>32) - ntpEpochOffset
+	nanos := (ntp & 0xffffffff) * uint64(time.Second) >> 32
+	return time.Unix(seconds, int64(nanos))
+}
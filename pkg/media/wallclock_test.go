@@ -0,0 +1,31 @@
+package media
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWallclockMapper(t *testing.T) {
+	m := NewWallclockMapper(90000)
+	_, ok := m.ToWallclock(0)
+	require.False(t, ok, "no sender report observed")
+
+	// 2022-02-16T16:42:40.25Z
+	m.Observe(&rtcp.SenderReport{NTPTime: 0xe5b7a800<<32 | 0x40000000, RTPTime: 0xffffff00})
+	at := time.Unix(1645029760, 25e7)
+
+	ts, ok := m.ToWallclock(0xffffff00)
+	require.True(t, ok)
+	require.True(t, at.Equal(ts), ts)
+
+	ts, _ = m.ToWallclock(90000 - 0x100)
+	require.True(t, at.Add(time.Second).Equal(ts), "timestamps wrapped around zero")
+	ts, _ = m.ToWallclock(0xffffff00 - 45000)
+	require.True(t, at.Add(-time.Second/2).Equal(ts), "timestamps before the report")
+
+	_, ok = NewWallclockMapper(0).ToWallclock(0)
+	require.False(t, ok)
+}
@@ -0,0 +1,58 @@
+// Package trackinfo builds livekit.TrackInfo records from pion webrtc tracks
+package trackinfo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/pion/webrtc/v3"
+)
+
+// ErrUnknownKind is returned for tracks which are neither audio nor video
+var ErrUnknownKind = errors.New("unknown track kind")
+
+// FromRemote builds a TrackInfo for a track subscribed from LiveKit, whose ID is the track SID.
+// The name of the track is not known from the webrtc track, and is given by the caller.
+func FromRemote(track *webrtc.TrackRemote, name string) (*livekit.TrackInfo, error) {
+	info, err := FromCodec(track.ID(), name, track.Kind(), track.Codec())
+	if err != nil {
+		return nil, err
+	}
+	info.Simulcast = track.RID() != ""
+	return info, nil
+}
+
+// FromCodec builds a TrackInfo from the kind and the negotiated codec of a track. The source is
+// not known from the codec and is left UNKNOWN for the caller to set, lksdk.TrackSourceOf guesses
+// it from the track otherwise. Kinds other than audio and video are rejected with ErrUnknownKind.
+func FromCodec(sid string, name string, kind webrtc.RTPCodecType, codec webrtc.RTPCodecParameters) (*livekit.TrackInfo, error) {
+	info := &livekit.TrackInfo{
+		Sid:      sid,
+		Name:     name,
+		MimeType: codec.MimeType,
+	}
+
+	switch kind {
+	case webrtc.RTPCodecTypeAudio:
+		info.Type = livekit.TrackType_AUDIO
+		// opus negotiated with usedtx=1 has DTX enabled
+		info.DisableDtx = !containsParameter(codec.SDPFmtpLine, "usedtx=1")
+	case webrtc.RTPCodecTypeVideo:
+		info.Type = livekit.TrackType_VIDEO
+	default:
+		return nil, fmt.Errorf("%w: %d for track %q", ErrUnknownKind, kind, sid)
+	}
+
+	return info, nil
+}
+
+func containsParameter(fmtpLine string, parameter string) bool {
+	for _, p := range strings.Split(fmtpLine, ";") {
+		if strings.TrimSpace(p) == parameter {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package trackinfo
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromCodec(t *testing.T) {
+	audio, err := FromCodec("TR_audio", "mic", webrtc.RTPCodecTypeAudio, webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:    webrtc.MimeTypeOpus,
+			SDPFmtpLine: "minptime=10; usedtx=1",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &livekit.TrackInfo{
+		Sid:      "TR_audio",
+		Name:     "mic",
+		Type:     livekit.TrackType_AUDIO,
+		MimeType: webrtc.MimeTypeOpus,
+	}, audio)
+
+	video, err := FromCodec("TR_video", "", webrtc.RTPCodecTypeVideo, webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8},
+	})
+	require.NoError(t, err)
+	require.Equal(t, livekit.TrackType_VIDEO, video.Type)
+	require.Equal(t, livekit.TrackSource_UNKNOWN, video.Source)
+	require.Equal(t, livekit.TrackSource_CAMERA, lksdk.TrackSourceOf(video, nil))
+	require.False(t, video.DisableDtx)
+
+	opus, err := FromCodec("TR_audio", "", webrtc.RTPCodecTypeAudio, webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus},
+	})
+	require.NoError(t, err)
+	require.True(t, opus.DisableDtx)
+
+	_, err = FromCodec("TR_unknown", "", webrtc.RTPCodecType(0), webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus},
+	})
+	require.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestFromCodec_ScreenShare(t *testing.T) {
+	screen, err := FromCodec("TR_screen", "screen", webrtc.RTPCodecTypeVideo, webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8},
+	})
+	require.NoError(t, err)
+	require.Equal(t, livekit.TrackSource_SCREEN_SHARE, lksdk.TrackSourceOf(screen, nil))
+
+	screen.Source = livekit.TrackSource_CAMERA
+	require.Equal(t, livekit.TrackSource_CAMERA, lksdk.TrackSourceOf(screen, nil), "sources set by the caller are kept")
+}
@@ -105,6 +105,7 @@ func NewLocalReaderTrack(in io.ReadCloser, mime string, options ...ReaderSampleP
 	for _, opt := range options {
 		opt(provider)
 	}
+	provider.Mime = NormalizeCodecMime(provider.Mime)
 
 	// check if mime type is supported
 	switch provider.Mime {
@@ -0,0 +1,248 @@
+package lksdk
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// RoomIsUnlimited returns true when the room does not limit the number of participants
+func RoomIsUnlimited(room *livekit.Room) bool {
+	return room.GetMaxParticipants() == 0
+}
+
+// RoomIsFull returns true when current participants reached the room's limit.
+// Rooms without a limit are never full.
+func RoomIsFull(room *livekit.Room, current uint32) bool {
+	return !RoomIsUnlimited(room) && current >= room.GetMaxParticipants()
+}
+
+// RoomRemainingCapacity returns how many more participants can join the room, 0 when it is full.
+// Unlimited rooms have a capacity of math.MaxUint32, so that any number of participants fits.
+func RoomRemainingCapacity(room *livekit.Room, current uint32) uint32 {
+	if RoomIsUnlimited(room) {
+		return math.MaxUint32
+	}
+	if RoomIsFull(room, current) {
+		return 0
+	}
+	return room.GetMaxParticipants() - current
+}
+
+// RoomFirstCodec returns the first enabled codec of the room.
+// It is nil-safe: it returns nil if room is nil or has no enabled codecs.
+func RoomFirstCodec(room *livekit.Room) *livekit.Codec {
+	if codecs := room.GetEnabledCodecs(); len(codecs) > 0 {
+		return codecs[0]
+	}
+	return nil
+}
+
+// RoomAge returns how long the room has existed at now, based on its creation time
+func RoomAge(room *livekit.Room, now time.Time) time.Duration {
+	return now.Sub(time.Unix(room.GetCreationTime(), 0))
+}
+
+// DefaultRoomEmptyTimeout is the empty timeout LiveKit servers use by default, applied by
+// RoomShouldReap to rooms whose EmptyTimeout is 0
+const DefaultRoomEmptyTimeout = 5 * time.Minute
+
+// RoomShouldReap returns true when the room has no participants and has been idle for at least
+// its empty timeout, or DefaultRoomEmptyTimeout when it is 0. A zero lastActivity means no activity
+// since the room was created.
+func RoomShouldReap(room *livekit.Room, participants uint32, now time.Time, lastActivity time.Time) bool {
+	if participants > 0 {
+		return false
+	}
+	if lastActivity.IsZero() {
+		lastActivity = time.Unix(room.GetCreationTime(), 0)
+	}
+	timeout := time.Duration(room.GetEmptyTimeout()) * time.Second
+	if timeout == 0 {
+		timeout = DefaultRoomEmptyTimeout
+	}
+	return now.Sub(lastActivity) >= timeout
+}
+
+// RoomApplyUpdate merges the fields of update named in mask into room. Fields are named as in the
+// protocol definition, such as "max_participants", and fields not set in update are cleared.
+// EnabledCodecs are merged by mime type instead of being replaced. If mask names an unknown field,
+// an error wrapping ErrUnknownField is returned and room is left unchanged. The fields are copied.
+func RoomApplyUpdate(room *livekit.Room, update *livekit.Room, mask []string) error {
+	fields := room.ProtoReflect().Descriptor().Fields()
+	for _, path := range mask {
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownField, path)
+		}
+	}
+
+	// the room must not share messages or lists with update, which the caller may modify later
+	update = proto.Clone(update).(*livekit.Room)
+	dst, src := room.ProtoReflect(), update.ProtoReflect()
+	for _, path := range mask {
+		fd := fields.ByName(protoreflect.Name(path))
+		switch {
+		case fd.Name() == "enabled_codecs":
+			room.EnabledCodecs = mergeCodecs(room.EnabledCodecs, update.EnabledCodecs)
+		case src.Has(fd):
+			dst.Set(fd, src.Get(fd))
+		default:
+			dst.Clear(fd)
+		}
+	}
+	return nil
+}
+
+// mergeCodecs replaces the codecs with the same mime type, and appends the others
+func mergeCodecs(codecs []*livekit.Codec, updates []*livekit.Codec) []*livekit.Codec {
+	for _, update := range updates {
+		found := false
+		for i, c := range codecs {
+			if CodecMimeEqual(c.Mime, update.Mime) {
+				codecs[i] = update
+				found = true
+				break
+			}
+		}
+		if !found {
+			codecs = append(codecs, update)
+		}
+	}
+	return codecs
+}
+
+// RoomNormalizeCodecs sorts the enabled codecs of the room by mime type, and removes the codecs whose
+// mime type is the same as a previous one once normalized with NormalizeCodecMime, so that rooms listing
+// the same codecs compare equal. The mime types of the codecs kept are replaced by their normalized form.
+func RoomNormalizeCodecs(room *livekit.Room) {
+	if room == nil {
+		return
+	}
+
+	codecs := make([]*livekit.Codec, 0, len(room.EnabledCodecs))
+	seen := make(map[string]bool, len(room.EnabledCodecs))
+	for _, c := range room.EnabledCodecs {
+		if c == nil {
+			continue
+		}
+		mime := NormalizeCodecMime(c.Mime)
+		key := strings.ToLower(mime)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		c.Mime = mime
+		codecs = append(codecs, c)
+	}
+	sort.SliceStable(codecs, func(i, j int) bool {
+		return strings.ToLower(codecs[i].Mime) < strings.ToLower(codecs[j].Mime)
+	})
+	room.EnabledCodecs = codecs
+}
+
+// RoomWithCodecs returns a copy of the room whose enabled codecs are copies of codecs, leaving room
+// and codecs untouched. Nil codecs are skipped. It is nil-safe: a nil room is copied as an empty one.
+func RoomWithCodecs(room *livekit.Room, codecs ...*livekit.Codec) *livekit.Room {
+	clone := &livekit.Room{}
+	if room != nil {
+		clone = proto.Clone(room).(*livekit.Room)
+	}
+
+	clone.EnabledCodecs = nil
+	for _, c := range codecs {
+		if c != nil {
+			clone.EnabledCodecs = append(clone.EnabledCodecs, proto.Clone(c).(*livekit.Codec))
+		}
+	}
+	return clone
+}
+
+// RoomToMap converts the fields set in room to a map keyed by their JSON names, for structured logging
+func RoomToMap(room *livekit.Room) map[string]interface{} {
+	return messageToMap(room.ProtoReflect())
+}
+
+// RoomMetadataAs decodes the room's JSON metadata into v.
+// Empty metadata is a no-op, v is left unchanged.
+func RoomMetadataAs(room *livekit.Room, v interface{}) error {
+	return unmarshalMetadata(room.GetMetadata(), v)
+}
+
+// SetRoomMetadataFrom sets the room's metadata to the JSON encoding of v.
+// A nil v clears the metadata.
+func SetRoomMetadataFrom(room *livekit.Room, v interface{}) error {
+	metadata, err := marshalMetadata(v)
+	if err != nil {
+		return err
+	}
+	room.Metadata = metadata
+	return nil
+}
+
+// SetRoomTURNCredentials sets the TURN credentials of the room, it should be preferred to assigning
+// TurnPassword directly. Both username and password must be set, ErrInvalidTURNCredentials is
+// returned otherwise and room is left unchanged. Only the password is stored, since the protocol
+// has no field for the username yet.
+func SetRoomTURNCredentials(room *livekit.Room, username, password string) error {
+	if username == "" || password == "" {
+		return ErrInvalidTURNCredentials
+	}
+	room.TurnPassword = password
+	return nil
+}
+
+// RotateRoomTURNCredentials sets the TURN credentials of the room to the ones returned by rotate,
+// such as a call to a credential store. Errors of rotate are returned, leaving room unchanged.
+func RotateRoomTURNCredentials(room *livekit.Room, rotate func() (username, password string, err error)) error {
+	username, password, err := rotate()
+	if err != nil {
+		return err
+	}
+	return SetRoomTURNCredentials(room, username, password)
+}
+
+// MediaProfile summarizes the tracks published in a room
+type MediaProfile struct {
+	VideoTracks int
+	AudioTracks int
+	// Codecs are the normalized mime types in use, sorted
+	Codecs []string
+	// MaxWidth and MaxHeight are the dimensions of the largest video track
+	MaxWidth, MaxHeight uint32
+}
+
+// RoomMediaProfile aggregates the tracks of the room's participants. Data tracks are not counted.
+func RoomMediaProfile(participants []*livekit.ParticipantInfo) MediaProfile {
+	var profile MediaProfile
+	codecs := make(map[string]struct{})
+	for _, p := range participants {
+		for _, t := range p.GetTracks() {
+			switch t.GetType() {
+			case livekit.TrackType_VIDEO:
+				profile.VideoTracks++
+				if uint64(t.Width)*uint64(t.Height) > uint64(profile.MaxWidth)*uint64(profile.MaxHeight) {
+					profile.MaxWidth, profile.MaxHeight = t.Width, t.Height
+				}
+			case livekit.TrackType_AUDIO:
+				profile.AudioTracks++
+			default:
+				continue
+			}
+			if t.MimeType != "" {
+				codecs[NormalizeCodecMime(t.MimeType)] = struct{}{}
+			}
+		}
+	}
+
+	for codec := range codecs {
+		profile.Codecs = append(profile.Codecs, codec)
+	}
+	sort.Strings(profile.Codecs)
+	return profile
+}
@@ -0,0 +1,214 @@
+package lksdk
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomCapacity(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		room := &livekit.Room{}
+		require.True(t, RoomIsUnlimited(room))
+		require.False(t, RoomIsFull(room, 1000))
+		require.Equal(t, uint32(math.MaxUint32), RoomRemainingCapacity(room, 1000))
+		require.Equal(t, uint32(math.MaxUint32), RoomRemainingCapacity(nil, 0))
+	})
+	t.Run("limited", func(t *testing.T) {
+		room := &livekit.Room{MaxParticipants: 2}
+		require.False(t, RoomIsUnlimited(room))
+		require.False(t, RoomIsFull(room, 1))
+		require.Equal(t, uint32(1), RoomRemainingCapacity(room, 1))
+		require.True(t, RoomIsFull(room, 2))
+		require.True(t, RoomIsFull(room, 3))
+		require.Equal(t, uint32(0), RoomRemainingCapacity(room, 3))
+	})
+}
+
+func TestRoomFirstCodec(t *testing.T) {
+	require.Nil(t, RoomFirstCodec(nil))
+	require.Nil(t, RoomFirstCodec(&livekit.Room{}))
+	require.Equal(t, "", RoomFirstCodec(nil).GetMime())
+
+	codec := &livekit.Codec{Mime: "video/VP8"}
+	require.Equal(t, codec, RoomFirstCodec(&livekit.Room{EnabledCodecs: []*livekit.Codec{codec}}))
+}
+
+func TestRoomAge(t *testing.T) {
+	room := &livekit.Room{CreationTime: 1000, EmptyTimeout: 300}
+	now := time.Unix(1600, 0)
+	require.Equal(t, 10*time.Minute, RoomAge(room, now))
+
+	t.Run("reap", func(t *testing.T) {
+		require.False(t, RoomShouldReap(room, 1, now, time.Time{}))
+		require.True(t, RoomShouldReap(room, 0, now, time.Time{}))
+		require.True(t, RoomShouldReap(room, 0, now, time.Unix(1300, 0)))
+		require.False(t, RoomShouldReap(room, 0, now, time.Unix(1301, 0)))
+
+		// rooms without empty timeout use the default one of the server
+		room = &livekit.Room{CreationTime: 1000}
+		require.False(t, RoomShouldReap(room, 0, time.Unix(1001, 0), time.Time{}))
+		require.False(t, RoomShouldReap(room, 0, time.Unix(1299, 0), time.Time{}))
+		require.True(t, RoomShouldReap(room, 0, time.Unix(1300, 0), time.Time{}))
+	})
+}
+
+func TestRoomApplyUpdate(t *testing.T) {
+	room := &livekit.Room{
+		Sid:             "RM_1",
+		Name:            "room",
+		MaxParticipants: 10,
+		Metadata:        "meta",
+		EnabledCodecs: []*livekit.Codec{
+			{Mime: "video/VP8"},
+			{Mime: "audio/opus", FmtpLine: "minptime=10"},
+		},
+	}
+	update := &livekit.Room{
+		Name:            "ignored",
+		MaxParticipants: 20,
+		EnabledCodecs: []*livekit.Codec{
+			{Mime: "audio/opus", FmtpLine: "useinbandfec=1"},
+			{Mime: "video/AV1"},
+		},
+	}
+
+	require.NoError(t, RoomApplyUpdate(room, update, []string{"max_participants", "metadata", "enabled_codecs"}))
+	require.Equal(t, "room", room.Name)
+	require.Equal(t, uint32(20), room.MaxParticipants)
+	require.Empty(t, room.Metadata)
+	require.Len(t, room.EnabledCodecs, 3)
+	require.Equal(t, "video/VP8", room.EnabledCodecs[0].Mime)
+	require.Equal(t, "useinbandfec=1", room.EnabledCodecs[1].FmtpLine)
+	require.Equal(t, "video/AV1", room.EnabledCodecs[2].Mime)
+
+	// the room does not share the codecs of the update
+	update.EnabledCodecs[0].FmtpLine = "changed"
+	update.EnabledCodecs[1] = &livekit.Codec{Mime: "video/H264"}
+	require.Equal(t, "useinbandfec=1", room.EnabledCodecs[1].FmtpLine)
+	require.Equal(t, "video/AV1", room.EnabledCodecs[2].Mime)
+
+	err := RoomApplyUpdate(room, update, []string{"name", "unknown"})
+	require.ErrorIs(t, err, ErrUnknownField)
+	require.Equal(t, "room", room.Name)
+}
+
+func TestRoomToMap(t *testing.T) {
+	m := RoomToMap(&livekit.Room{
+		Sid:             "RM_1",
+		MaxParticipants: 10,
+		EnabledCodecs:   []*livekit.Codec{{Mime: "video/VP8"}},
+	})
+	require.Equal(t, map[string]interface{}{
+		"sid":             "RM_1",
+		"maxParticipants": uint32(10),
+		"enabledCodecs": []interface{}{
+			map[string]interface{}{"mime": "video/VP8"},
+		},
+	}, m)
+}
+
+func TestRoomMetadata(t *testing.T) {
+	room := &livekit.Room{}
+	var m map[string]string
+	require.NoError(t, RoomMetadataAs(room, &m))
+	require.Nil(t, m)
+
+	require.NoError(t, SetRoomMetadataFrom(room, map[string]string{"topic": "standup"}))
+	require.Equal(t, `{"topic":"standup"}`, room.Metadata)
+	require.NoError(t, RoomMetadataAs(room, &m))
+	require.Equal(t, map[string]string{"topic": "standup"}, m)
+}
+
+func TestRoomTURNCredentials(t *testing.T) {
+	room := &livekit.Room{TurnPassword: "old"}
+	require.ErrorIs(t, SetRoomTURNCredentials(room, "", "secret"), ErrInvalidTURNCredentials)
+	require.ErrorIs(t, SetRoomTURNCredentials(room, "user", ""), ErrInvalidTURNCredentials)
+	require.Equal(t, "old", room.TurnPassword)
+
+	require.NoError(t, SetRoomTURNCredentials(room, "user", "secret"))
+	require.Equal(t, "secret", room.TurnPassword)
+
+	rotateErr := errors.New("store unavailable")
+	require.ErrorIs(t, RotateRoomTURNCredentials(room, func() (string, string, error) {
+		return "", "", rotateErr
+	}), rotateErr)
+	require.Equal(t, "secret", room.TurnPassword)
+
+	require.NoError(t, RotateRoomTURNCredentials(room, func() (string, string, error) {
+		return "user", "rotated", nil
+	}))
+	require.Equal(t, "rotated", room.TurnPassword)
+}
+
+func TestRoomMediaProfile(t *testing.T) {
+	require.Equal(t, MediaProfile{}, RoomMediaProfile(nil))
+
+	profile := RoomMediaProfile([]*livekit.ParticipantInfo{
+		{Tracks: []*livekit.TrackInfo{
+			{Type: livekit.TrackType_VIDEO, MimeType: "video/vp8", Width: 1280, Height: 720},
+			{Type: livekit.TrackType_AUDIO, MimeType: "audio/opus"},
+		}},
+		{Tracks: []*livekit.TrackInfo{
+			{Type: livekit.TrackType_VIDEO, MimeType: "VP8", Width: 1920, Height: 1080},
+			{Type: livekit.TrackType_VIDEO, MimeType: "video/H264", Width: 640, Height: 360},
+			{Type: livekit.TrackType_DATA, MimeType: "application/data"},
+		}},
+		nil,
+	})
+	require.Equal(t, MediaProfile{
+		VideoTracks: 3,
+		AudioTracks: 1,
+		Codecs:      []string{"audio/opus", "video/H264", "video/VP8"},
+		MaxWidth:    1920,
+		MaxHeight:   1080,
+	}, profile)
+}
+
+func TestRoomNormalizeCodecs(t *testing.T) {
+	RoomNormalizeCodecs(nil)
+
+	room := &livekit.Room{
+		EnabledCodecs: []*livekit.Codec{
+			{Mime: "video/vp8"},
+			{Mime: "audio/opus", FmtpLine: "useinbandfec=1"},
+			{Mime: "VP8", FmtpLine: "duplicate"},
+			nil,
+			{Mime: "video/H264"},
+			{Mime: "Audio/Opus"},
+		},
+	}
+	RoomNormalizeCodecs(room)
+
+	var mimes []string
+	for _, c := range room.EnabledCodecs {
+		mimes = append(mimes, c.Mime)
+	}
+	require.Equal(t, []string{"audio/opus", "video/H264", "video/VP8"}, mimes)
+	require.Equal(t, "useinbandfec=1", room.EnabledCodecs[0].FmtpLine, "the first occurrence is kept")
+	require.Empty(t, room.EnabledCodecs[2].FmtpLine)
+}
+
+func TestRoomWithCodecs(t *testing.T) {
+	vp8 := &livekit.Codec{Mime: "video/VP8"}
+	room := &livekit.Room{Name: "room", EnabledCodecs: []*livekit.Codec{vp8}}
+
+	opus := &livekit.Codec{Mime: "audio/opus"}
+	updated := RoomWithCodecs(room, opus, nil)
+	require.Equal(t, "room", updated.Name)
+	require.Len(t, updated.EnabledCodecs, 1)
+	require.Equal(t, "audio/opus", updated.EnabledCodecs[0].Mime)
+
+	updated.EnabledCodecs[0].Mime = "audio/red"
+	updated.Name = "other"
+	require.Equal(t, "audio/opus", opus.Mime, "codecs are copied")
+	require.Equal(t, "room", room.Name)
+	require.Equal(t, []*livekit.Codec{vp8}, room.EnabledCodecs)
+
+	require.Empty(t, RoomWithCodecs(room).EnabledCodecs)
+	require.Len(t, RoomWithCodecs(nil, vp8).EnabledCodecs, 1)
+}
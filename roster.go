@@ -0,0 +1,125 @@
+package lksdk
+
+import (
+	"sort"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// Roster is a set of participants keyed by identity. It is not safe for concurrent use.
+type Roster struct {
+	participants map[string]*livekit.ParticipantInfo
+}
+
+// RosterDelta lists the changes between two rosters, each list is sorted by join time
+type RosterDelta struct {
+	Joined  []*livekit.ParticipantInfo
+	Left    []*livekit.ParticipantInfo
+	Updated []*livekit.ParticipantInfo
+}
+
+func NewRoster(participants ...*livekit.ParticipantInfo) *Roster {
+	r := &Roster{
+		participants: make(map[string]*livekit.ParticipantInfo, len(participants)),
+	}
+	for _, p := range participants {
+		r.Add(p)
+	}
+	return r
+}
+
+// Add adds the participant, replacing any participant with the same identity
+func (r *Roster) Add(p *livekit.ParticipantInfo) {
+	r.participants[p.Identity] = p
+}
+
+// Remove removes the participant with the given identity, returns false if there is none
+func (r *Roster) Remove(identity string) bool {
+	if _, ok := r.participants[identity]; !ok {
+		return false
+	}
+	delete(r.participants, identity)
+	return true
+}
+
+// Get returns the participant with the given identity, or nil
+func (r *Roster) Get(identity string) *livekit.ParticipantInfo {
+	return r.participants[identity]
+}
+
+func (r *Roster) Len() int {
+	return len(r.participants)
+}
+
+// List returns the participants sorted by join time, then identity
+func (r *Roster) List() []*livekit.ParticipantInfo {
+	list := make([]*livekit.ParticipantInfo, 0, len(r.participants))
+	for _, p := range r.participants {
+		list = append(list, p)
+	}
+	sortByJoinTime(list)
+	return list
+}
+
+// Diff returns the changes from r to other, following the same rules as RosterDiff
+func (r *Roster) Diff(other *Roster) RosterDelta {
+	var delta RosterDelta
+	delta.Joined, delta.Left, delta.Updated = diffParticipants(r.participants, other.participants)
+	return delta
+}
+
+// rosterVolatileFields are ignored to tell changed participants, the version of a participant is
+// bumped with every update
+var rosterVolatileFields = []string{"version"}
+
+// RosterDiff returns the participants of next who joined since prev, the participants of prev who
+// left, and the participants of next whose fields changed, except their version. Participants are
+// keyed by identity: a participant of next whose SID differs from the one in prev rejoined, it is
+// both in left, with its previous info, and in joined. Nil participants are skipped, and the last
+// one wins among participants with the same identity. Each list is sorted by join time.
+func RosterDiff(prev, next []*livekit.ParticipantInfo) (joined, left, changed []*livekit.ParticipantInfo) {
+	return diffParticipants(participantsByIdentity(prev), participantsByIdentity(next))
+}
+
+func diffParticipants(prev, next map[string]*livekit.ParticipantInfo) (joined, left, changed []*livekit.ParticipantInfo) {
+	for identity, p := range next {
+		switch before, ok := prev[identity]; {
+		case !ok:
+			joined = append(joined, p)
+		case before.Sid != p.Sid:
+			left = append(left, before)
+			joined = append(joined, p)
+		case !ParticipantsEquivalent(before, p, rosterVolatileFields...):
+			changed = append(changed, p)
+		}
+	}
+	for identity, p := range prev {
+		if _, ok := next[identity]; !ok {
+			left = append(left, p)
+		}
+	}
+
+	sortByJoinTime(joined)
+	sortByJoinTime(left)
+	sortByJoinTime(changed)
+	return
+}
+
+func participantsByIdentity(participants []*livekit.ParticipantInfo) map[string]*livekit.ParticipantInfo {
+	byIdentity := make(map[string]*livekit.ParticipantInfo, len(participants))
+	for _, p := range participants {
+		if p != nil {
+			byIdentity[p.Identity] = p
+		}
+	}
+	return byIdentity
+}
+
+func sortByJoinTime(list []*livekit.ParticipantInfo) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].JoinedAt != list[j].JoinedAt {
+			return list[i].JoinedAt < list[j].JoinedAt
+		}
+		return list[i].Identity < list[j].Identity
+	})
+}
@@ -0,0 +1,106 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoster(t *testing.T) {
+	alice := &livekit.ParticipantInfo{Identity: "alice", JoinedAt: 20}
+	bob := &livekit.ParticipantInfo{Identity: "bob", JoinedAt: 10}
+	carol := &livekit.ParticipantInfo{Identity: "carol", JoinedAt: 10}
+
+	r := NewRoster(alice, bob)
+	require.Equal(t, 2, r.Len())
+	require.Equal(t, alice, r.Get("alice"))
+	require.Nil(t, r.Get("carol"))
+
+	r.Add(carol)
+	require.Equal(t, []*livekit.ParticipantInfo{bob, carol, alice}, r.List())
+
+	require.True(t, r.Remove("carol"))
+	require.False(t, r.Remove("carol"))
+
+	t.Run("diff", func(t *testing.T) {
+		updatedAlice := &livekit.ParticipantInfo{Identity: "alice", JoinedAt: 20, Metadata: "away"}
+		other := NewRoster(updatedAlice, carol)
+		require.Equal(t, RosterDelta{
+			Joined:  []*livekit.ParticipantInfo{carol},
+			Left:    []*livekit.ParticipantInfo{bob},
+			Updated: []*livekit.ParticipantInfo{updatedAlice},
+		}, r.Diff(other))
+		require.Equal(t, RosterDelta{}, r.Diff(NewRoster(alice, bob)))
+	})
+}
+
+func TestRosterDiff(t *testing.T) {
+	alice := &livekit.ParticipantInfo{Sid: "PA_a", Identity: "alice", JoinedAt: 20}
+	bob := &livekit.ParticipantInfo{Sid: "PA_b", Identity: "bob", JoinedAt: 10}
+	carol := &livekit.ParticipantInfo{Sid: "PA_c", Identity: "carol", JoinedAt: 30}
+	prev := []*livekit.ParticipantInfo{alice, bob, carol}
+
+	t.Run("unchanged", func(t *testing.T) {
+		bumped := &livekit.ParticipantInfo{Sid: "PA_a", Identity: "alice", JoinedAt: 20, Version: 5}
+		joined, left, changed := RosterDiff(prev, []*livekit.ParticipantInfo{carol, bumped, nil, bob})
+		require.Empty(t, joined)
+		require.Empty(t, left)
+		require.Empty(t, changed, "versions are ignored")
+	})
+
+	t.Run("joined, left and changed", func(t *testing.T) {
+		dave := &livekit.ParticipantInfo{Sid: "PA_d", Identity: "dave", JoinedAt: 40}
+		away := &livekit.ParticipantInfo{Sid: "PA_a", Identity: "alice", JoinedAt: 20, Metadata: "away"}
+		joined, left, changed := RosterDiff(prev, []*livekit.ParticipantInfo{away, carol, dave})
+		require.Equal(t, []*livekit.ParticipantInfo{dave}, joined)
+		require.Equal(t, []*livekit.ParticipantInfo{bob}, left)
+		require.Equal(t, []*livekit.ParticipantInfo{away}, changed)
+	})
+
+	t.Run("rejoined", func(t *testing.T) {
+		rejoined := &livekit.ParticipantInfo{Sid: "PA_b2", Identity: "bob", JoinedAt: 50}
+		joined, left, changed := RosterDiff(prev, []*livekit.ParticipantInfo{alice, rejoined, carol})
+		require.Equal(t, []*livekit.ParticipantInfo{rejoined}, joined)
+		require.Equal(t, []*livekit.ParticipantInfo{bob}, left)
+		require.Empty(t, changed)
+	})
+
+	t.Run("duplicate identities", func(t *testing.T) {
+		updated := &livekit.ParticipantInfo{Sid: "PA_a", Identity: "alice", JoinedAt: 20, Name: "Alice"}
+		_, _, changed := RosterDiff(prev, []*livekit.ParticipantInfo{alice, bob, carol, updated})
+		require.Equal(t, []*livekit.ParticipantInfo{updated}, changed, "the last one wins")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		joined, left, changed := RosterDiff(nil, prev)
+		require.Equal(t, []*livekit.ParticipantInfo{bob, alice, carol}, joined)
+		require.Empty(t, left)
+		require.Empty(t, changed)
+
+		joined, left, _ = RosterDiff(prev, nil)
+		require.Empty(t, joined)
+		require.Equal(t, []*livekit.ParticipantInfo{bob, alice, carol}, left)
+	})
+}
+
+func TestRosterDiffMatchesRoster(t *testing.T) {
+	prev := []*livekit.ParticipantInfo{
+		{Sid: "PA_a", Identity: "alice", JoinedAt: 20},
+		{Sid: "PA_b", Identity: "bob", JoinedAt: 10},
+		{Sid: "PA_c", Identity: "carol", JoinedAt: 30},
+	}
+	next := []*livekit.ParticipantInfo{
+		{Sid: "PA_a", Identity: "alice", JoinedAt: 20, Version: 3},
+		{Sid: "PA_b2", Identity: "bob", JoinedAt: 50},
+		{Sid: "PA_c", Identity: "carol", JoinedAt: 30, Metadata: "away"},
+		{Sid: "PA_d", Identity: "dave", JoinedAt: 40},
+	}
+
+	joined, left, changed := RosterDiff(prev, next)
+	delta := NewRoster(prev...).Diff(NewRoster(next...))
+	require.Equal(t, RosterDelta{Joined: joined, Left: left, Updated: changed}, delta)
+	require.Equal(t, []*livekit.ParticipantInfo{next[3], next[1]}, delta.Joined)
+	require.Equal(t, []*livekit.ParticipantInfo{prev[1]}, delta.Left)
+	require.Equal(t, []*livekit.ParticipantInfo{next[2]}, delta.Updated)
+}
@@ -0,0 +1,64 @@
+package lksdk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/livekit/protocol/utils"
+)
+
+type SIDKind string
+
+const (
+	SIDKindRoom        SIDKind = "room"
+	SIDKindParticipant SIDKind = "participant"
+	SIDKindTrack       SIDKind = "track"
+	SIDKindEgress      SIDKind = "egress"
+)
+
+var sidPrefixes = map[string]SIDKind{
+	utils.RoomPrefix:        SIDKindRoom,
+	utils.ParticipantPrefix: SIDKindParticipant,
+	utils.TrackPrefix:       SIDKindTrack,
+	utils.EgressPrefix:      SIDKindEgress,
+}
+
+// ParseSID returns the kind of object identified by s, from its prefix. It returns an error
+// wrapping ErrInvalidSID if the prefix is unknown or the rest of the SID is not alphanumeric.
+func ParseSID(s string) (SIDKind, error) {
+	idx := strings.IndexByte(s, '_')
+	if idx < 0 {
+		return "", fmt.Errorf("%w: %q has no prefix", ErrInvalidSID, s)
+	}
+
+	kind, ok := sidPrefixes[s[:idx+1]]
+	if !ok {
+		return "", fmt.Errorf("%w: %q has an unknown prefix", ErrInvalidSID, s)
+	}
+
+	id := s[idx+1:]
+	if id == "" {
+		return "", fmt.Errorf("%w: %q is empty", ErrInvalidSID, s)
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return "", fmt.Errorf("%w: %q is not alphanumeric", ErrInvalidSID, s)
+		}
+	}
+	return kind, nil
+}
+
+func IsRoomSID(s string) bool {
+	kind, err := ParseSID(s)
+	return err == nil && kind == SIDKindRoom
+}
+
+func IsParticipantSID(s string) bool {
+	kind, err := ParseSID(s)
+	return err == nil && kind == SIDKindParticipant
+}
+
+func IsTrackSID(s string) bool {
+	kind, err := ParseSID(s)
+	return err == nil && kind == SIDKindTrack
+}
@@ -0,0 +1,29 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSID(t *testing.T) {
+	kind, err := ParseSID(utils.NewGuid(utils.RoomPrefix))
+	require.NoError(t, err)
+	require.Equal(t, SIDKindRoom, kind)
+
+	kind, err = ParseSID("EG_abc123")
+	require.NoError(t, err)
+	require.Equal(t, SIDKindEgress, kind)
+
+	for _, sid := range []string{"", "RM", "RM_", "XX_abc", "PA_abc-def", "abc_"} {
+		_, err = ParseSID(sid)
+		require.ErrorIs(t, err, ErrInvalidSID, sid)
+	}
+
+	require.True(t, IsRoomSID("RM_abc"))
+	require.False(t, IsRoomSID("PA_abc"))
+	require.True(t, IsParticipantSID(utils.NewGuid(utils.ParticipantPrefix)))
+	require.True(t, IsTrackSID(utils.NewGuid(utils.TrackPrefix)))
+	require.False(t, IsTrackSID("TR_"))
+}
@@ -10,8 +10,6 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pion/webrtc/v3"
 	"go.uber.org/atomic"
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/protocol/livekit"
 )
@@ -161,14 +159,14 @@ func (c *SignalClient) SendRequest(req *livekit.SignalRequest) error {
 	if c.conn == nil {
 		return errors.New("client is not connected")
 	}
-	payload, err := proto.Marshal(req)
+	messageType, payload, err := EncodeSignalRequest(req)
 	if err != nil {
 		return err
 	}
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	return c.conn.WriteMessage(websocket.BinaryMessage, payload)
+	return c.conn.WriteMessage(messageType, payload)
 }
 
 func (c *SignalClient) SendUpdateTrackSettings(settings *livekit.UpdateTrackSettings) error {
@@ -193,16 +191,9 @@ func (c *SignalClient) ReadResponse() (*livekit.SignalResponse, error) {
 			return nil, err
 		}
 
-		msg := &livekit.SignalResponse{}
 		switch messageType {
-		case websocket.BinaryMessage:
-			// protobuf encoded
-			err := proto.Unmarshal(payload, msg)
-			return msg, err
-		case websocket.TextMessage:
-			// json encoded
-			err := protojson.Unmarshal(payload, msg)
-			return msg, err
+		case websocket.BinaryMessage, websocket.TextMessage:
+			return DecodeSignalResponse(messageType, payload)
 		default:
 			return nil, nil
 		}
@@ -0,0 +1,42 @@
+package lksdk
+
+import (
+	"fmt"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// EncodeSignalRequest encodes a request the way SignalClient sends it over the signal WebSocket.
+// It returns the WebSocket message type along with the payload: requests are protobuf encoded,
+// in binary messages.
+func EncodeSignalRequest(req *livekit.SignalRequest) (messageType int, payload []byte, err error) {
+	payload, err = proto.Marshal(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	return websocket.BinaryMessage, payload, nil
+}
+
+// DecodeSignalResponse decodes a response received over the signal WebSocket. Binary messages are
+// protobuf encoded, and text messages JSON encoded. For other message types, an error wrapping
+// ErrUnsupportedSignalMessage is returned.
+func DecodeSignalResponse(messageType int, payload []byte) (*livekit.SignalResponse, error) {
+	msg := &livekit.SignalResponse{}
+	switch messageType {
+	case websocket.BinaryMessage:
+		if err := proto.Unmarshal(payload, msg); err != nil {
+			return nil, err
+		}
+	case websocket.TextMessage:
+		if err := protojson.Unmarshal(payload, msg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: message type %d", ErrUnsupportedSignalMessage, messageType)
+	}
+	return msg, nil
+}
@@ -0,0 +1,48 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSignalCodec(t *testing.T) {
+	req := &livekit.SignalRequest{
+		Message: &livekit.SignalRequest_Mute{
+			Mute: &livekit.MuteTrackRequest{Sid: "TR_a", Muted: true},
+		},
+	}
+	messageType, payload, err := EncodeSignalRequest(req)
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, messageType)
+	decodedReq := &livekit.SignalRequest{}
+	require.NoError(t, proto.Unmarshal(payload, decodedReq))
+	require.True(t, proto.Equal(req, decodedReq))
+
+	res := &livekit.SignalResponse{
+		Message: &livekit.SignalResponse_Leave{
+			Leave: &livekit.LeaveRequest{CanReconnect: true},
+		},
+	}
+	binary, err := proto.Marshal(res)
+	require.NoError(t, err)
+	decoded, err := DecodeSignalResponse(websocket.BinaryMessage, binary)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(res, decoded))
+
+	text, err := protojson.Marshal(res)
+	require.NoError(t, err)
+	decoded, err = DecodeSignalResponse(websocket.TextMessage, text)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(res, decoded))
+
+	_, err = DecodeSignalResponse(websocket.TextMessage, []byte("{"))
+	require.Error(t, err)
+	_, err = DecodeSignalResponse(websocket.PingMessage, nil)
+	require.ErrorIs(t, err, ErrUnsupportedSignalMessage)
+	require.Contains(t, err.Error(), "message type 9")
+}
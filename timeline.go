@@ -0,0 +1,86 @@
+package lksdk
+
+import (
+	"sort"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// ParticipantTimeline collects the sessions of participants in a room, to compute its occupancy over
+// time. Times are unix timestamps in seconds, like ParticipantInfo.JoinedAt. A participant is present
+// from its join time, included, to its leave time, excluded. Sessions of the same identity that overlap
+// are counted once. It is not safe for concurrent use.
+type ParticipantTimeline struct {
+	sessions []timelineSession
+}
+
+type timelineSession struct {
+	identity         string
+	joinedAt, leftAt int64
+}
+
+func NewParticipantTimeline() *ParticipantTimeline {
+	return &ParticipantTimeline{}
+}
+
+// Add adds a session of the participant with the given identity. leftAt is 0 if the participant did not leave.
+func (t *ParticipantTimeline) Add(identity string, joinedAt, leftAt int64) {
+	t.sessions = append(t.sessions, timelineSession{identity: identity, joinedAt: joinedAt, leftAt: leftAt})
+}
+
+// AddParticipant adds a session of p, which joined at p.JoinedAt
+func (t *ParticipantTimeline) AddParticipant(p *livekit.ParticipantInfo, leftAt int64) {
+	t.Add(p.GetIdentity(), p.GetJoinedAt(), leftAt)
+}
+
+// Occupancy returns the number of participants present at the given time
+func (t *ParticipantTimeline) Occupancy(at time.Time) int {
+	ts := at.Unix()
+	present := make(map[string]struct{})
+	for _, s := range t.sessions {
+		if s.joinedAt <= ts && (s.leftAt == 0 || ts < s.leftAt) {
+			present[s.identity] = struct{}{}
+		}
+	}
+	return len(present)
+}
+
+// PeakOccupancy returns the highest number of participants present at the same time
+func (t *ParticipantTimeline) PeakOccupancy() int {
+	type event struct {
+		at       int64
+		identity string
+		delta    int
+	}
+	events := make([]event, 0, 2*len(t.sessions))
+	for _, s := range t.sessions {
+		if s.leftAt != 0 && s.leftAt <= s.joinedAt {
+			continue
+		}
+		events = append(events, event{at: s.joinedAt, identity: s.identity, delta: 1})
+		if s.leftAt != 0 {
+			events = append(events, event{at: s.leftAt, identity: s.identity, delta: -1})
+		}
+	}
+	// participants leaving are not present anymore when others join at the same time
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].at != events[j].at {
+			return events[i].at < events[j].at
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	sessions := make(map[string]int)
+	peak := 0
+	for _, e := range events {
+		sessions[e.identity] += e.delta
+		if sessions[e.identity] == 0 {
+			delete(sessions, e.identity)
+		}
+		if len(sessions) > peak {
+			peak = len(sessions)
+		}
+	}
+	return peak
+}
@@ -0,0 +1,38 @@
+package lksdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParticipantTimeline(t *testing.T) {
+	timeline := NewParticipantTimeline()
+	require.Zero(t, timeline.PeakOccupancy())
+
+	timeline.Add("a", 100, 200)
+	timeline.Add("b", 150, 0)
+	timeline.Add("a", 180, 300) // reconnection overlapping the first session
+	timeline.AddParticipant(&livekit.ParticipantInfo{Identity: "c", JoinedAt: 300}, 400)
+	timeline.Add("d", 500, 500) // empty session
+
+	for at, expected := range map[int64]int{
+		99:   0,
+		100:  1,
+		150:  2,
+		199:  2,
+		250:  2,
+		300:  2,
+		400:  1,
+		500:  1,
+		1000: 1,
+	} {
+		require.Equal(t, expected, timeline.Occupancy(time.Unix(at, 0)), "at %d", at)
+	}
+	require.Equal(t, 2, timeline.PeakOccupancy(), "a leaves when c joins")
+
+	timeline.Add("e", 160, 170)
+	require.Equal(t, 3, timeline.PeakOccupancy())
+}
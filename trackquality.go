@@ -0,0 +1,48 @@
+package lksdk
+
+import (
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/proto"
+)
+
+// TrackQualityMonitor detects changes of the published quality of tracks, from successive
+// TrackInfo updates. It is not safe for concurrent use.
+type TrackQualityMonitor struct {
+	// OnQualityChange is called when the width, height or simulcast flag of a track changed.
+	// old and new are copies of the previous and the current TrackInfo.
+	OnQualityChange func(old, new *livekit.TrackInfo)
+
+	tracks map[string]*livekit.TrackInfo
+}
+
+func NewTrackQualityMonitor(onQualityChange func(old, new *livekit.TrackInfo)) *TrackQualityMonitor {
+	return &TrackQualityMonitor{
+		OnQualityChange: onQualityChange,
+		tracks:          make(map[string]*livekit.TrackInfo),
+	}
+}
+
+// Update records the current info of a track, keyed by SID, and returns true if its quality
+// changed since the previous update. The first update of a track is not a change.
+func (m *TrackQualityMonitor) Update(t *livekit.TrackInfo) bool {
+	old := m.tracks[t.Sid]
+	current := proto.Clone(t).(*livekit.TrackInfo)
+	m.tracks[t.Sid] = current
+	if old == nil || !trackQualityChanged(old, current) {
+		return false
+	}
+
+	if m.OnQualityChange != nil {
+		m.OnQualityChange(old, proto.Clone(current).(*livekit.TrackInfo))
+	}
+	return true
+}
+
+// Remove forgets the track with the given SID, such as when it was unpublished
+func (m *TrackQualityMonitor) Remove(sid string) {
+	delete(m.tracks, sid)
+}
+
+func trackQualityChanged(old, new *livekit.TrackInfo) bool {
+	return old.Width != new.Width || old.Height != new.Height || old.Simulcast != new.Simulcast
+}
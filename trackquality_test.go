@@ -0,0 +1,41 @@
+package lksdk
+
+import (
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackQualityMonitor(t *testing.T) {
+	type change struct {
+		old, new *livekit.TrackInfo
+	}
+	var changes []change
+	m := NewTrackQualityMonitor(func(old, new *livekit.TrackInfo) {
+		changes = append(changes, change{old, new})
+	})
+
+	track := &livekit.TrackInfo{Sid: "TR_a", Width: 1920, Height: 1080}
+	require.False(t, m.Update(track))
+
+	track.Muted = true
+	require.False(t, m.Update(track), "other fields are not quality changes")
+
+	track.Width, track.Height = 1280, 720
+	require.True(t, m.Update(track))
+	require.Len(t, changes, 1)
+	require.Equal(t, uint32(1920), changes[0].old.Width)
+	require.Equal(t, uint32(1280), changes[0].new.Width)
+
+	track.Simulcast = true
+	require.True(t, m.Update(track))
+	require.Len(t, changes, 2)
+
+	require.False(t, m.Update(&livekit.TrackInfo{Sid: "TR_b", Width: 640}), "tracks are keyed by SID")
+
+	m.Remove("TR_a")
+	track.Width = 640
+	require.False(t, m.Update(track))
+	require.Len(t, changes, 2)
+}
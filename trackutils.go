@@ -0,0 +1,221 @@
+package lksdk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// StreamDescriptor describes a track like a stream object of ffprobe's JSON output
+type StreamDescriptor struct {
+	// Index is not known from the track, callers listing several tracks should set it
+	Index     int               `json:"index"`
+	CodecName string            `json:"codec_name,omitempty"`
+	CodecType string            `json:"codec_type"`
+	Width     uint32            `json:"width,omitempty"`
+	Height    uint32            `json:"height,omitempty"`
+	ID        string            `json:"id,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// TrackStreamDescriptor converts a track to a StreamDescriptor. The codec name is the
+// lowercase subtype of the track's mime type, such as "vp8" for "video/VP8".
+// It is nil-safe: a nil track has the zero StreamDescriptor.
+func TrackStreamDescriptor(t *livekit.TrackInfo) StreamDescriptor {
+	if t == nil {
+		return StreamDescriptor{}
+	}
+
+	d := StreamDescriptor{
+		CodecType: strings.ToLower(t.Type.String()),
+		ID:        t.Sid,
+	}
+
+	if mime := t.MimeType; mime != "" {
+		if idx := strings.IndexByte(mime, '/'); idx >= 0 {
+			mime = mime[idx+1:]
+		}
+		d.CodecName = strings.ToLower(mime)
+	}
+
+	if t.Type == livekit.TrackType_VIDEO {
+		d.Width = t.Width
+		d.Height = t.Height
+	}
+
+	if t.Name != "" {
+		d.Tags = map[string]string{"title": t.Name}
+	}
+	return d
+}
+
+// NewVideoTrackInfo returns a video track with the given SID, name and dimensions
+func NewVideoTrackInfo(sid, name string, width, height uint32) *livekit.TrackInfo {
+	return &livekit.TrackInfo{
+		Sid:    sid,
+		Name:   name,
+		Type:   livekit.TrackType_VIDEO,
+		Width:  width,
+		Height: height,
+	}
+}
+
+// NewAudioTrackInfo returns an audio track with the given SID and name, its dimensions are left at zero
+func NewAudioTrackInfo(sid, name string) *livekit.TrackInfo {
+	return &livekit.TrackInfo{
+		Sid:  sid,
+		Name: name,
+		Type: livekit.TrackType_AUDIO,
+	}
+}
+
+// TrackIsMedia returns true for audio and video tracks, and false for data tracks.
+// Recorders should reject other tracks with ErrNotMediaTrack.
+func TrackIsMedia(t *livekit.TrackInfo) bool {
+	switch t.GetType() {
+	case livekit.TrackType_AUDIO, livekit.TrackType_VIDEO:
+		return true
+	}
+	return false
+}
+
+// ValidateTrackCodec returns an error wrapping ErrTrackCodecMismatch unless the kind of the codec, the
+// type part of its mime type, is the type of the track: video codecs for video tracks and audio codecs
+// for audio tracks. Mime types without type are accepted for known codecs, see NormalizeCodecMime.
+// Tracks other than audio and video, and nil tracks, are rejected with ErrNotMediaTrack.
+func ValidateTrackCodec(track *livekit.TrackInfo, codecMime string) error {
+	if track == nil || !TrackIsMedia(track) {
+		return ErrNotMediaTrack
+	}
+
+	mime := NormalizeCodecMime(codecMime)
+	kind := strings.ToLower(track.Type.String())
+	if i := strings.IndexByte(mime, '/'); i < 0 || !strings.EqualFold(mime[:i], kind) {
+		return fmt.Errorf("%w: %q for %s track %q", ErrTrackCodecMismatch, codecMime, kind, track.Sid)
+	}
+	return nil
+}
+
+// TrackSourceFunc guesses the source of a track which does not set it, see TrackSourceOf
+type TrackSourceFunc func(t *livekit.TrackInfo) livekit.TrackSource
+
+// screenShareNameHints are the words of track names GuessTrackSource takes for screen shares
+var screenShareNameHints = []string{"screen", "share", "display"}
+
+// TrackSourceOf returns the source of the track, or the one guessed with guess when the track does
+// not set it, as published by older clients. A nil guess defaults to GuessTrackSource.
+func TrackSourceOf(t *livekit.TrackInfo, guess TrackSourceFunc) livekit.TrackSource {
+	if source := t.GetSource(); source != livekit.TrackSource_UNKNOWN {
+		return source
+	}
+	if guess == nil {
+		guess = GuessTrackSource
+	}
+	return guess(t)
+}
+
+// GuessTrackSource classifies a track from its name and type: tracks whose name contains "screen",
+// "share" or "display", in any case, are screen shares, SCREEN_SHARE for video and SCREEN_SHARE_AUDIO
+// for audio. Other video tracks are CAMERA, other audio tracks MICROPHONE, and data or nil tracks UNKNOWN.
+func GuessTrackSource(t *livekit.TrackInfo) livekit.TrackSource {
+	if t == nil || !TrackIsMedia(t) {
+		return livekit.TrackSource_UNKNOWN
+	}
+
+	name := strings.ToLower(t.GetName())
+	screenShare := false
+	for _, hint := range screenShareNameHints {
+		if strings.Contains(name, hint) {
+			screenShare = true
+			break
+		}
+	}
+
+	switch {
+	case t.GetType() == livekit.TrackType_VIDEO && screenShare:
+		return livekit.TrackSource_SCREEN_SHARE
+	case t.GetType() == livekit.TrackType_VIDEO:
+		return livekit.TrackSource_CAMERA
+	case screenShare:
+		return livekit.TrackSource_SCREEN_SHARE_AUDIO
+	}
+	return livekit.TrackSource_MICROPHONE
+}
+
+// FilterMediaTracks returns the audio and video tracks, in order
+func FilterMediaTracks(tracks []*livekit.TrackInfo) []*livekit.TrackInfo {
+	var media []*livekit.TrackInfo
+	for _, t := range tracks {
+		if TrackIsMedia(t) {
+			media = append(media, t)
+		}
+	}
+	return media
+}
+
+// trackTypeRank orders video before audio, and both before data and unknown types
+func trackTypeRank(t livekit.TrackType) int {
+	switch t {
+	case livekit.TrackType_VIDEO:
+		return 0
+	case livekit.TrackType_AUDIO:
+		return 1
+	case livekit.TrackType_DATA:
+		return 2
+	}
+	return 3
+}
+
+// SortTracks sorts tracks in place in canonical order: video tracks first, then audio,
+// then data tracks, and by SID within each type. The order does not depend on the
+// order the server returned the tracks in.
+func SortTracks(tracks []*livekit.TrackInfo) {
+	sort.Slice(tracks, func(i, j int) bool {
+		ri, rj := trackTypeRank(tracks[i].GetType()), trackTypeRank(tracks[j].GetType())
+		if ri != rj {
+			return ri < rj
+		}
+		return tracks[i].GetSid() < tracks[j].GetSid()
+	})
+}
+
+// SortTracksByType sorts tracks in place by type only, in the same type order as SortTracks.
+// The sort is stable, tracks of the same type keep their relative order.
+func SortTracksByType(tracks []*livekit.TrackInfo) {
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return trackTypeRank(tracks[i].GetType()) < trackTypeRank(tracks[j].GetType())
+	})
+}
+
+// EffectiveCodec resolves the codec of a track among the room's enabled codecs. The track's mime
+// type is used when it is set, matched with CodecMimeEqual, and returned as a Codec with no fmtp
+// line if the room does not list it. Otherwise, the codec is the first enabled codec of the
+// track's type, the one clients prefer when publishing. false is returned when neither applies.
+func EffectiveCodec(track *livekit.TrackInfo, room *livekit.Room) (*livekit.Codec, bool) {
+	if mime := track.GetMimeType(); mime != "" {
+		for _, c := range room.GetEnabledCodecs() {
+			if CodecMimeEqual(c.Mime, mime) {
+				return c, true
+			}
+		}
+		return &livekit.Codec{Mime: NormalizeCodecMime(mime)}, true
+	}
+
+	var prefix string
+	switch track.GetType() {
+	case livekit.TrackType_AUDIO:
+		prefix = "audio/"
+	case livekit.TrackType_VIDEO:
+		prefix = "video/"
+	default:
+		return nil, false
+	}
+	for _, c := range room.GetEnabledCodecs() {
+		if mime := NormalizeCodecMime(c.Mime); len(mime) > len(prefix) && strings.EqualFold(mime[:len(prefix)], prefix) {
+			return c, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,153 @@
+package lksdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackStreamDescriptor(t *testing.T) {
+	t.Run("video", func(t *testing.T) {
+		d := TrackStreamDescriptor(&livekit.TrackInfo{
+			Sid:      "TR_video",
+			Type:     livekit.TrackType_VIDEO,
+			Name:     "camera",
+			MimeType: "video/VP8",
+			Width:    1280,
+			Height:   720,
+		})
+		b, err := json.Marshal(d)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"index": 0,
+			"codec_name": "vp8",
+			"codec_type": "video",
+			"width": 1280,
+			"height": 720,
+			"id": "TR_video",
+			"tags": {"title": "camera"}
+		}`, string(b))
+	})
+
+	t.Run("audio", func(t *testing.T) {
+		d := TrackStreamDescriptor(&livekit.TrackInfo{
+			Type:     livekit.TrackType_AUDIO,
+			MimeType: "audio/opus",
+			Width:    10,
+		})
+		require.Equal(t, StreamDescriptor{CodecName: "opus", CodecType: "audio"}, d)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		require.Equal(t, StreamDescriptor{}, TrackStreamDescriptor(nil))
+	})
+}
+
+func TestNewTrackInfo(t *testing.T) {
+	video := NewVideoTrackInfo("TR_video", "camera", 1280, 720)
+	require.Equal(t, livekit.TrackType_VIDEO, video.Type)
+	require.Equal(t, "camera", video.Name)
+	require.Equal(t, uint32(1280), video.Width)
+	require.Equal(t, uint32(720), video.Height)
+
+	audio := NewAudioTrackInfo("TR_audio", "microphone")
+	require.Equal(t, livekit.TrackType_AUDIO, audio.Type)
+	require.Equal(t, "TR_audio", audio.Sid)
+	require.Zero(t, audio.Width)
+	require.Zero(t, audio.Height)
+}
+
+func TestTrackIsMedia(t *testing.T) {
+	audio := &livekit.TrackInfo{Sid: "TR_audio", Type: livekit.TrackType_AUDIO}
+	video := &livekit.TrackInfo{Sid: "TR_video", Type: livekit.TrackType_VIDEO}
+	data := &livekit.TrackInfo{Sid: "TR_data", Type: livekit.TrackType_DATA}
+
+	require.True(t, TrackIsMedia(audio))
+	require.True(t, TrackIsMedia(video))
+	require.False(t, TrackIsMedia(data))
+	require.False(t, TrackIsMedia(&livekit.TrackInfo{Type: livekit.TrackType(10)}))
+
+	require.Equal(t, []*livekit.TrackInfo{audio, video}, FilterMediaTracks([]*livekit.TrackInfo{audio, data, video}))
+}
+
+func TestValidateTrackCodec(t *testing.T) {
+	audio := NewAudioTrackInfo("TR_audio", "microphone")
+	video := NewVideoTrackInfo("TR_video", "camera", 640, 480)
+
+	require.NoError(t, ValidateTrackCodec(video, "video/VP8"))
+	require.NoError(t, ValidateTrackCodec(video, "av1"))
+	require.NoError(t, ValidateTrackCodec(audio, "audio/opus"))
+	require.ErrorIs(t, ValidateTrackCodec(audio, "video/VP8"), ErrTrackCodecMismatch)
+	require.ErrorIs(t, ValidateTrackCodec(video, "Opus"), ErrTrackCodecMismatch)
+	require.ErrorIs(t, ValidateTrackCodec(video, "unknown"), ErrTrackCodecMismatch)
+	require.ErrorIs(t, ValidateTrackCodec(&livekit.TrackInfo{Type: livekit.TrackType_DATA}, "video/VP8"), ErrNotMediaTrack)
+	require.ErrorIs(t, ValidateTrackCodec(nil, "video/VP8"), ErrNotMediaTrack)
+}
+
+func TestTrackSourceOf(t *testing.T) {
+	for _, tc := range []struct {
+		track  *livekit.TrackInfo
+		source livekit.TrackSource
+	}{
+		{NewVideoTrackInfo("TR_1", "camera", 640, 480), livekit.TrackSource_CAMERA},
+		{NewVideoTrackInfo("TR_2", "Screen Share", 1920, 1080), livekit.TrackSource_SCREEN_SHARE},
+		{NewVideoTrackInfo("TR_3", "display-1", 1920, 1080), livekit.TrackSource_SCREEN_SHARE},
+		{NewAudioTrackInfo("TR_4", "mic"), livekit.TrackSource_MICROPHONE},
+		{NewAudioTrackInfo("TR_5", "screenshare_audio"), livekit.TrackSource_SCREEN_SHARE_AUDIO},
+		{&livekit.TrackInfo{Type: livekit.TrackType_DATA, Name: "screen"}, livekit.TrackSource_UNKNOWN},
+		{&livekit.TrackInfo{Type: livekit.TrackType_VIDEO, Name: "screen", Source: livekit.TrackSource_CAMERA}, livekit.TrackSource_CAMERA},
+		{nil, livekit.TrackSource_UNKNOWN},
+	} {
+		require.Equal(t, tc.source, TrackSourceOf(tc.track, nil), tc.track.GetName())
+	}
+
+	guess := func(*livekit.TrackInfo) livekit.TrackSource { return livekit.TrackSource_SCREEN_SHARE }
+	require.Equal(t, livekit.TrackSource_SCREEN_SHARE, TrackSourceOf(NewVideoTrackInfo("TR_1", "camera", 0, 0), guess))
+	require.Equal(t, livekit.TrackSource_MICROPHONE, TrackSourceOf(&livekit.TrackInfo{Source: livekit.TrackSource_MICROPHONE}, guess))
+}
+
+func TestSortTracks(t *testing.T) {
+	audioA := &livekit.TrackInfo{Sid: "TR_a", Type: livekit.TrackType_AUDIO}
+	audioB := &livekit.TrackInfo{Sid: "TR_b", Type: livekit.TrackType_AUDIO}
+	videoC := &livekit.TrackInfo{Sid: "TR_c", Type: livekit.TrackType_VIDEO}
+	videoD := &livekit.TrackInfo{Sid: "TR_d", Type: livekit.TrackType_VIDEO}
+	data := &livekit.TrackInfo{Sid: "TR_0", Type: livekit.TrackType_DATA}
+
+	tracks := []*livekit.TrackInfo{audioB, data, videoD, audioA, videoC}
+	SortTracks(tracks)
+	require.Equal(t, []*livekit.TrackInfo{videoC, videoD, audioA, audioB, data}, tracks)
+
+	tracks = []*livekit.TrackInfo{audioB, data, videoD, audioA, videoC}
+	SortTracksByType(tracks)
+	require.Equal(t, []*livekit.TrackInfo{videoD, videoC, audioB, audioA, data}, tracks)
+}
+
+func TestEffectiveCodec(t *testing.T) {
+	vp8 := &livekit.Codec{Mime: "video/VP8"}
+	h264 := &livekit.Codec{Mime: "video/H264", FmtpLine: "profile-level-id=42e01f"}
+	opus := &livekit.Codec{Mime: "audio/opus"}
+	room := &livekit.Room{EnabledCodecs: []*livekit.Codec{opus, h264, vp8}}
+
+	c, ok := EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_VIDEO, MimeType: "video/vp8"}, room)
+	require.True(t, ok)
+	require.Equal(t, vp8, c)
+
+	c, ok = EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_VIDEO, MimeType: "av1"}, room)
+	require.True(t, ok)
+	require.Equal(t, "video/AV1", c.Mime)
+
+	c, ok = EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_VIDEO}, room)
+	require.True(t, ok)
+	require.Equal(t, h264, c)
+
+	c, ok = EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_AUDIO}, room)
+	require.True(t, ok)
+	require.Equal(t, opus, c)
+
+	_, ok = EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_DATA}, room)
+	require.False(t, ok)
+	_, ok = EffectiveCodec(&livekit.TrackInfo{Type: livekit.TrackType_VIDEO}, nil)
+	require.False(t, ok)
+}
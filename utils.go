@@ -6,6 +6,8 @@ import (
 
 	"github.com/pion/webrtc/v3"
 	"github.com/thoas/go-funk"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
 	"github.com/livekit/protocol/livekit"
 )
@@ -73,3 +75,103 @@ func ToWebsocketURL(url string) string {
 	}
 	return url
 }
+
+// EstimatedWireSize returns the size of the message once marshaled
+func EstimatedWireSize(m proto.Message) int {
+	return proto.Size(m)
+}
+
+var knownCodecMimes = []string{
+	webrtc.MimeTypeH264,
+	webrtc.MimeTypeVP8,
+	webrtc.MimeTypeVP9,
+	webrtc.MimeTypeAV1,
+	webrtc.MimeTypeOpus,
+	webrtc.MimeTypeG722,
+	webrtc.MimeTypePCMU,
+	webrtc.MimeTypePCMA,
+}
+
+// NormalizeCodecMime returns the canonical form of known codec mime types, such as
+// "video/VP8" for "video/vp8", "VP8" or "video/VP8;foo=bar", whose parameters are dropped.
+// Unknown mime types are returned unchanged.
+func NormalizeCodecMime(s string) string {
+	s = strings.TrimSpace(s)
+	name := s
+	if i := strings.IndexByte(name, ';'); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	for _, mime := range knownCodecMimes {
+		if strings.EqualFold(name, mime) || strings.EqualFold(name, mime[strings.IndexByte(mime, '/')+1:]) {
+			return mime
+		}
+	}
+	return s
+}
+
+// CodecMimeEqual compares codec mime types, ignoring case and a missing type prefix of known codecs
+func CodecMimeEqual(a, b string) bool {
+	return strings.EqualFold(NormalizeCodecMime(a), NormalizeCodecMime(b))
+}
+
+// messageToMap converts the populated fields of a message to a map keyed by JSON field names.
+// Nested messages are converted to maps, and enums to their names.
+func messageToMap(m protoreflect.Message) map[string]interface{} {
+	out := make(map[string]interface{})
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList():
+			list := v.List()
+			values := make([]interface{}, list.Len())
+			for i := range values {
+				values[i] = fieldValue(fd, list.Get(i))
+			}
+			out[fd.JSONName()] = values
+		case fd.IsMap():
+			values := make(map[string]interface{})
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				values[k.String()] = fieldValue(fd.MapValue(), mv)
+				return true
+			})
+			out[fd.JSONName()] = values
+		default:
+			out[fd.JSONName()] = fieldValue(fd, v)
+		}
+		return true
+	})
+	return out
+}
+
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return messageToMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+// unmarshalMetadata decodes JSON metadata into v, empty metadata leaves v unchanged
+func unmarshalMetadata(metadata string, v interface{}) error {
+	if metadata == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(metadata), v)
+}
+
+// marshalMetadata encodes v as JSON metadata, nil is encoded as empty metadata
+func marshalMetadata(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
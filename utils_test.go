@@ -3,7 +3,10 @@ package lksdk
 import (
 	"testing"
 
+	"github.com/pion/webrtc/v3"
 	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
 )
 
 func TestToHttpURL(t *testing.T) {
@@ -23,3 +26,32 @@ func TestToWebsocketURL(t *testing.T) {
 		require.Equal(t, "wss://url.com", ToWebsocketURL("https://url.com"))
 	})
 }
+
+func TestNormalizeCodecMime(t *testing.T) {
+	for _, s := range []string{"video/VP8", "video/vp8", "VP8", " vp8 ", "video/VP8; foo=bar"} {
+		require.Equal(t, webrtc.MimeTypeVP8, NormalizeCodecMime(s))
+	}
+	require.Equal(t, webrtc.MimeTypeOpus, NormalizeCodecMime("OPUS"))
+	require.Equal(t, "video/unknown", NormalizeCodecMime("video/unknown"))
+	require.Equal(t, "video/unknown;foo=bar", NormalizeCodecMime("video/unknown;foo=bar"))
+
+	require.True(t, CodecMimeEqual("vp8", "video/VP8"))
+	require.True(t, CodecMimeEqual("video/Unknown", "video/unknown"))
+	require.False(t, CodecMimeEqual("video/VP8", "video/VP9"))
+}
+
+func TestMessageToMap(t *testing.T) {
+	packet := &livekit.DataPacket{
+		Kind: livekit.DataPacket_LOSSY,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{ParticipantSid: "PA_1", Payload: []byte{1}},
+		},
+	}
+	require.Equal(t, map[string]interface{}{
+		"kind": "LOSSY",
+		"user": map[string]interface{}{
+			"participantSid": "PA_1",
+			"payload":        []byte{1},
+		},
+	}, messageToMap(packet.ProtoReflect()))
+}